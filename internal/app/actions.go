@@ -0,0 +1,465 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/hooks"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/components"
+	"github.com/lazar0169/brewst/internal/ui/views"
+)
+
+// actionKind is a mutating brew operation on packages
+type actionKind string
+
+const (
+	actionInstall    actionKind = "install"
+	actionReinstall  actionKind = "reinstall"
+	actionUninstall  actionKind = "uninstall"
+	actionUpgrade    actionKind = "upgrade"
+	actionPin        actionKind = "pin"
+	actionUnpin      actionKind = "unpin"
+	actionLink       actionKind = "link"
+	actionCleanup    actionKind = "cleanup"
+	actionAutoremove actionKind = "autoremove"
+	actionTap        actionKind = "tap"
+)
+
+// action is an operation requested by a view
+type action struct {
+	kind     actionKind
+	packages []brew.Package
+	outdated []brew.OutdatedPackage // Upgrades only, for the hook versions
+	upgrades []views.UpgradeBatch   // Upgrades only, grouped by install options
+	all      bool                   // Upgrades and autoremove only, brew upgrade or autoremove of everything
+	tap      string                 // Taps only
+	install  brew.InstallOptions    // Installs and reinstalls only
+	version  string                 // Links only, the version to link
+}
+
+func (a action) names() []string {
+	names := make([]string, len(a.packages))
+	for i, pkg := range a.packages {
+		names[i] = pkg.Name
+	}
+	return names
+}
+
+// label names the operation in the logs, like "install jq"
+func (a action) label() string {
+	return string(a.kind) + " " + a.target()
+}
+
+// target names the packages, and the version for links
+func (a action) target() string {
+	switch {
+	case a.kind == actionTap:
+		return a.tap
+	case a.kind == actionUpgrade && a.all:
+		return "all packages"
+	case a.kind == actionAutoremove && len(a.packages) == 0:
+		return "unused dependencies"
+	}
+	target := strings.Join(a.names(), ", ")
+	if a.version != "" {
+		target += " " + a.version
+	}
+	return target
+}
+
+// progress describes the running operation, like "Installing jq..."
+func (a action) progress() string {
+	verbs := map[actionKind]string{
+		actionInstall:    "Installing",
+		actionReinstall:  "Reinstalling",
+		actionUninstall:  "Uninstalling",
+		actionUpgrade:    "Upgrading",
+		actionPin:        "Pinning",
+		actionUnpin:      "Unpinning",
+		actionLink:       "Linking",
+		actionCleanup:    "Cleaning up",
+		actionAutoremove: "Removing",
+		actionTap:        "Tapping",
+	}
+	return fmt.Sprintf("%s %s...", verbs[a.kind], a.target())
+}
+
+// success describes the finished operation, like "Installed jq"
+func (a action) success() string {
+	verbs := map[actionKind]string{
+		actionInstall:    "Installed",
+		actionReinstall:  "Reinstalled",
+		actionUninstall:  "Uninstalled",
+		actionUpgrade:    "Upgraded",
+		actionPin:        "Pinned",
+		actionUnpin:      "Unpinned",
+		actionLink:       "Linked",
+		actionCleanup:    "Cleaned up",
+		actionAutoremove: "Removed",
+		actionTap:        "Tapped",
+	}
+	return fmt.Sprintf("%s %s", verbs[a.kind], a.target())
+}
+
+// operation describes the action to the hooks
+func (a action) operation() hooks.Operation {
+	op := hooks.Operation{Name: string(a.kind), Packages: a.names()}
+	if a.kind == actionAutoremove {
+		// Removed dependencies get the uninstall hooks
+		op.Name = string(actionUninstall)
+	}
+	if len(a.packages) != 1 {
+		return op
+	}
+	pkg := a.packages[0]
+	op.PackageType = string(pkg.Type)
+	switch a.kind {
+	case actionInstall:
+		op.ToVersion = pkg.Version
+	case actionReinstall:
+		op.FromVersion = pkg.Version
+		op.ToVersion = pkg.Version
+	case actionUninstall:
+		op.FromVersion = pkg.Version
+	case actionUpgrade:
+		op.FromVersion = a.outdated[0].CurrentVersion
+		op.ToVersion = a.outdated[0].LatestVersion
+	case actionLink:
+		op.FromVersion = pkg.LinkedVersion
+		op.ToVersion = a.version
+	}
+	return op
+}
+
+// run runs the brew command for the action
+func (a action) run(ctx context.Context, client brew.Client) error {
+	switch a.kind {
+	case actionUpgrade:
+		return views.RunUpgrade(ctx, client, a.upgrades)
+	case actionCleanup:
+		return client.CleanupPackages(ctx, a.names())
+	case actionAutoremove:
+		if a.all {
+			return client.Autoremove(ctx)
+		}
+		for _, name := range a.names() {
+			if err := client.Uninstall(ctx, name, brew.UninstallOptions{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case actionTap:
+		return client.TapAdd(ctx, a.tap)
+	}
+
+	pkg := a.packages[0]
+	switch a.kind {
+	case actionInstall:
+		return client.Install(ctx, pkg.Name, a.install)
+	case actionReinstall:
+		return client.Reinstall(ctx, pkg.Name, a.install)
+	case actionUninstall:
+		return client.Uninstall(ctx, pkg.Name, brew.UninstallOptions{Cask: pkg.Type == brew.TypeCask})
+	case actionPin:
+		return client.Pin(ctx, pkg.Name)
+	case actionUnpin:
+		return client.Unpin(ctx, pkg.Name)
+	case actionLink:
+		return client.SwitchVersion(ctx, pkg.Name, a.version)
+	}
+	return fmt.Errorf("unknown action %q", a.kind)
+}
+
+// actionDispatcher runs the actions views request: it checks the team
+// policies, asks for confirmation, runs the action between its hooks and
+// reports the result. Installs ask for their options instead, and pinning is
+// cheap to undo and runs without asking.
+type actionDispatcher struct {
+	client brew.Client
+	config *state.Config
+
+	dialog  *components.Dialog        // Confirms the pending action
+	notice  *components.Dialog        // Explains why a policy blocked an action
+	options *components.OptionsDialog // Asks for the options of the pending install
+	pending *action
+	install *pendingInstall
+
+	installs map[string]action // Running installs by label, their options are remembered when they succeed
+}
+
+func newActionDispatcher(client brew.Client, config *state.Config) *actionDispatcher {
+	return &actionDispatcher{
+		client:   client,
+		config:   config,
+		dialog:   components.NewConfirmDialog("Confirm", ""),
+		notice:   components.NewDialog("Blocked by policy", "", components.DialogError),
+		options:  components.NewOptionsDialog("Install"),
+		installs: make(map[string]action),
+	}
+}
+
+// handle takes action requests and the dispatcher's own messages. It reports
+// whether msg was one of them.
+func (d *actionDispatcher) handle(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case views.NavigateToDetailsMsg:
+		return func() tea.Msg { return NavigateMsg(ViewDetails) }, true
+
+	case views.RequestInstallMsg:
+		return d.requestInstall(msg.Package), true
+
+	case views.RequestReinstallMsg:
+		opts := d.config.InstallOptions[msg.Package.Name]
+		opts.Cask = msg.Package.Type == brew.TypeCask
+		a := action{kind: actionReinstall, packages: []brew.Package{msg.Package}, install: opts}
+		question := fmt.Sprintf("Reinstall %s?", msg.Package.Name)
+		if flags := brew.ReinstallArgs(msg.Package.Name, opts)[2:]; len(flags) > 0 && !opts.Cask {
+			question = fmt.Sprintf("Reinstall %s with %s?", msg.Package.Name, strings.Join(flags, " "))
+		}
+		d.confirm(a, question)
+		return nil, true
+
+	case views.RequestUninstallMsg:
+		a := action{kind: actionUninstall, packages: []brew.Package{msg.Package}}
+		d.confirm(a, fmt.Sprintf("Uninstall %s?", msg.Package.Name))
+		return nil, true
+
+	case views.RequestUpgradeMsg:
+		if len(msg.Packages) == 0 {
+			return nil, true
+		}
+		a := d.upgrade(msg.Packages)
+		warnings := views.UpgradeWarnings(d.config, msg.Packages)
+		d.confirm(a, views.WithUpgradeWarnings(fmt.Sprintf("Upgrade %s?", strings.Join(a.names(), ", ")), warnings))
+		return nil, true
+
+	case views.RequestUpgradeAllMsg:
+		d.requestUpgradeAll(msg.Packages)
+		return nil, true
+
+	case views.RequestCleanupMsg:
+		if len(msg.Packages) == 0 {
+			return nil, true
+		}
+		return d.run(action{kind: actionCleanup, packages: packagesNamed(msg.Packages, brew.TypeFormula)}), true
+
+	case views.RequestAutoremoveMsg:
+		if len(msg.Packages) == 0 && !msg.All {
+			return nil, true
+		}
+		return d.run(action{kind: actionAutoremove, packages: packagesNamed(msg.Packages, brew.TypeFormula), all: msg.All}), true
+
+	case views.RequestTapMsg:
+		if reason := views.TapBlocked(d.config, msg.Tap); reason != "" {
+			d.block(reason)
+			return nil, true
+		}
+		d.confirm(action{kind: actionTap, tap: msg.Tap}, fmt.Sprintf("Tap %s?", msg.Tap))
+		return nil, true
+
+	case views.TogglePinMsg:
+		kind := actionPin
+		if msg.Pinned {
+			kind = actionUnpin
+		}
+		return d.run(action{kind: kind, packages: []brew.Package{{Name: msg.PackageName}}}), true
+
+	case views.RequestLinkMsg:
+		a := action{kind: actionLink, packages: []brew.Package{msg.Package}, version: msg.Version}
+		question := fmt.Sprintf("Link %s %s?", msg.Package.Name, msg.Version)
+		if linked := msg.Package.LinkedVersion; linked != "" {
+			question = fmt.Sprintf("Link %s %s in place of %s?", msg.Package.Name, msg.Version, linked)
+		}
+		d.confirm(a, question)
+		return nil, true
+
+	case installLookupMsg:
+		d.showInstallOptions(msg)
+		return nil, true
+
+	case components.OptionsDialogMsg:
+		if d.install == nil {
+			return nil, false
+		}
+		install := d.install
+		d.install = nil
+		if !msg.Confirmed {
+			return nil, true
+		}
+		pkg := install.packages[msg.Choice]
+		return d.run(action{kind: actionInstall, packages: []brew.Package{pkg}, install: installOptions(pkg, msg.Options)}), true
+
+	case components.DialogMsg:
+		// Answers to the views' own dialogs go to the views
+		if d.pending == nil {
+			return nil, false
+		}
+		a := *d.pending
+		d.pending = nil
+		if !msg.Confirmed {
+			return nil, true
+		}
+		return d.run(a), true
+	}
+	return nil, false
+}
+
+// requestInstall checks the install policy before looking up the package to
+// ask how to install it
+func (d *actionDispatcher) requestInstall(pkg brew.Package) tea.Cmd {
+	if reason := views.InstallBlocked(d.config, pkg); reason != "" {
+		return func() tea.Msg { return views.PolicyBlockedMsg{Reason: reason} }
+	}
+	return d.lookupInstall(pkg)
+}
+
+// packagesNamed returns packages of one type by name
+func packagesNamed(names []string, pkgType brew.PackageType) []brew.Package {
+	packages := make([]brew.Package, len(names))
+	for i, name := range names {
+		packages[i] = brew.Package{Name: name, Type: pkgType}
+	}
+	return packages
+}
+
+// upgrade plans an upgrade of the packages with the options they were
+// installed with
+func (d *actionDispatcher) upgrade(packages []brew.OutdatedPackage) action {
+	a := action{kind: actionUpgrade, outdated: packages}
+	opts := brew.UpgradeOptions{}
+	for _, outdated := range packages {
+		a.packages = append(a.packages, brew.Package{Name: outdated.Name, Type: outdated.Type, Version: outdated.CurrentVersion})
+		opts.Greedy = opts.Greedy || outdated.AutoUpdates
+	}
+	a.upgrades = views.PlanUpgrade(d.config, a.names(), nil, opts)
+	return a
+}
+
+// requestUpgradeAll asks before upgrading the outdated packages the
+// auto-upgrade policy allows, all of them when it allows everything
+func (d *actionDispatcher) requestUpgradeAll(outdated []brew.OutdatedPackage) {
+	if len(outdated) == 0 {
+		return
+	}
+	if !d.config.AutoUpgrade.Restricted() {
+		a := action{kind: actionUpgrade, all: true, outdated: outdated}
+		a.upgrades = views.PlanUpgrade(d.config, nil, outdated, brew.UpgradeOptions{Greedy: d.config.GreedyCasks})
+		question := fmt.Sprintf("Upgrade all %d outdated packages?", len(outdated))
+		d.confirm(a, views.WithUpgradeWarnings(question, views.UpgradeWarnings(d.config, outdated)))
+		return
+	}
+
+	allowed, held := views.AutoUpgradePlan(d.config.AutoUpgrade, outdated)
+	if len(allowed) == 0 {
+		reason := "No updates are allowed by the auto-upgrade policy"
+		if len(held) > 0 {
+			reason += "\n\n" + views.HeldSummary(held)
+		}
+		d.block(reason)
+		return
+	}
+	question := fmt.Sprintf("Upgrade the %d of %d outdated packages the auto-upgrade policy allows?", len(allowed), len(outdated))
+	if len(held) > 0 {
+		question = views.HeldSummary(held) + "\n\n" + question
+	}
+	d.confirm(d.upgrade(allowed), views.WithUpgradeWarnings(question, views.UpgradeWarnings(d.config, allowed)))
+}
+
+// confirm asks before running an action
+func (d *actionDispatcher) confirm(a action, question string) {
+	d.pending = &a
+	d.dialog.SetMessage(question)
+	d.dialog.Show()
+}
+
+// block explains why a policy stopped an action
+func (d *actionDispatcher) block(reason string) {
+	d.notice.SetMessage(reason)
+	d.notice.Show()
+}
+
+// run reports the action as started, then runs it and reports how it went
+func (d *actionDispatcher) run(a action) tea.Cmd {
+	// The policy file may have changed while the dialog was open, and the
+	// package may have been chosen as the other type. Blocked actions never
+	// start, so they don't count as running.
+	reason := ""
+	switch a.kind {
+	case actionInstall:
+		reason = views.InstallBlocked(d.config, a.packages[0])
+	case actionTap:
+		reason = views.TapBlocked(d.config, a.tap)
+	}
+	if reason != "" {
+		return func() tea.Msg { return views.PolicyBlockedMsg{Reason: reason} }
+	}
+
+	if a.kind == actionInstall {
+		d.installs[a.label()] = a
+	}
+
+	started := func() tea.Msg {
+		return views.ActionStartedMsg{Op: a.label(), Message: a.progress()}
+	}
+	return tea.Sequence(started, func() tea.Msg {
+		ctx := context.Background()
+		logs, err := hooks.NewRunner(d.config.Hooks).Wrap(ctx, a.operation(), func() error {
+			return a.run(ctx, d.client)
+		})
+		return views.ActionFinishedMsg{Op: a.label(), Msg: a.success(), Err: err, Logs: logs}
+	})
+}
+
+// finished remembers the options of an install once it succeeds, so a failed
+// install doesn't change how the package is reinstalled and upgraded
+func (d *actionDispatcher) finished(msg views.ActionFinishedMsg) error {
+	a, ok := d.installs[msg.Op]
+	if !ok {
+		return nil
+	}
+	delete(d.installs, msg.Op)
+	if msg.Err != nil {
+		return nil
+	}
+	d.config.RememberInstallOptions(a.packages[0].Name, a.install)
+	if err := d.config.Save(); err != nil {
+		return fmt.Errorf("failed to save the install options of %s: %w", a.packages[0].Name, err)
+	}
+	return nil
+}
+
+// visible reports whether one of the dispatcher's dialogs is open
+func (d *actionDispatcher) visible() bool {
+	return d.dialog.IsVisible() || d.notice.IsVisible() || d.options.IsVisible()
+}
+
+// update passes input to the open dialog
+func (d *actionDispatcher) update(msg tea.Msg) tea.Cmd {
+	if d.notice.IsVisible() {
+		// Nothing waits for the notice's answer
+		d.notice, _ = d.notice.Update(msg)
+		return nil
+	}
+	var cmd tea.Cmd
+	if d.options.IsVisible() {
+		d.options, cmd = d.options.Update(msg)
+		return cmd
+	}
+	d.dialog, cmd = d.dialog.Update(msg)
+	return cmd
+}
+
+// overlay draws the open dialog over content
+func (d *actionDispatcher) overlay(content string, width, height int) string {
+	if d.notice.IsVisible() {
+		return d.notice.Overlay(content, width, height)
+	}
+	if d.options.IsVisible() {
+		return d.options.Overlay(content, width, height)
+	}
+	return d.dialog.Overlay(content, width, height)
+}
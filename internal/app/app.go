@@ -25,6 +25,11 @@ const (
 	ViewOutdated
 	ViewTaps
 	ViewDiagnostics
+	ViewVulnerabilities
+	ViewLicenses
+	ViewPolicy
+	ViewDrift
+	ViewDebug
 )
 
 // Model is the main application model
@@ -33,13 +38,21 @@ type Model struct {
 	brewClient brew.Client
 	state      *state.State
 	config     *state.Config
+	vulns      *vulnerabilityChecker
+	actions    *actionDispatcher
 
 	// UI components
 	header    *components.Header
 	statusBar *components.StatusBar
-	dialog    *components.Dialog
 	spinner   spinner.Model
 
+	// Command palette, and what each of its items does
+	palette        *components.Palette
+	paletteEntries []paletteEntry
+
+	// ":" command line
+	cmdline *components.CommandLine
+
 	// Views
 	currentView ViewType
 	viewStack   []ViewType
@@ -50,27 +63,40 @@ type Model struct {
 	height int
 
 	// Application state
-	ready bool
-	err   error
+	ready           bool
+	checkingUpdates bool  // Background update check running
+	actionsRunning  int   // Actions started and not finished yet
+	deferredCheck   bool  // Update check waiting for the running actions
+	err             error // Config that failed to load, nothing runs until it's fixed
 }
 
 // Msg types for navigation
 type (
-	NavigateMsg     ViewType
-	BackMsg         struct{}
-	ErrorMsg        struct{ Err error }
-	SuccessMsg      struct{ Msg string }
+	NavigateMsg       ViewType
+	BackMsg           struct{}
+	ErrorMsg          struct{ Err error }
+	SuccessMsg        struct{ Msg string }
 	PackagesLoadedMsg struct{ Packages []brew.Package }
-	OutdatedLoadedMsg struct{ Packages []brew.OutdatedPackage }
-	TapsLoadedMsg struct{ Taps []brew.Tap }
+	OutdatedLoadedMsg struct {
+		Packages []brew.OutdatedPackage
+		Err      error
+	}
+	TapsLoadedMsg      struct{ Taps []brew.Tap }
+	DiskUsageLoadedMsg struct{ Usage []brew.DiskUsage }
 )
 
-// New creates a new application model
-func New() *Model {
-	config, _ := state.LoadConfig()
+// New creates a new application model. With debug, or "debug" in the
+// config, every brew command is recorded to the trace file.
+func New(debug bool) *Model {
+	config, configErr := state.LoadConfig()
 	favorites, _ := state.LoadFavorites()
 
 	appState := state.NewState()
+	if debug || config.Debug {
+		if err := startTracing(config); err != nil {
+			appState.SetError(err)
+		}
+	}
 	appState.Favorites = favorites
 	appState.ShowFormulae = config.ShowFormulaByDefault
 	appState.ShowCasks = config.ShowCasksByDefault
@@ -79,13 +105,18 @@ func New() *Model {
 
 	// Initialize views
 	viewsMap := make(map[ViewType]tea.Model)
-	viewsMap[ViewHome] = views.NewDashboardView(brewClient, appState) // Use dashboard as home
+	viewsMap[ViewHome] = views.NewDashboardView(brewClient, appState, config) // Use dashboard as home
 	viewsMap[ViewInstalled] = views.NewInstalledView(brewClient, appState)
 	viewsMap[ViewSearch] = views.NewSearchView(brewClient, appState)
-	viewsMap[ViewDetails] = views.NewDetailsView(brewClient, appState)
-	viewsMap[ViewOutdated] = views.NewOutdatedView(brewClient, appState)
-	viewsMap[ViewTaps] = views.NewTapsView(brewClient, appState)
+	viewsMap[ViewDetails] = views.NewDetailsView(brewClient, appState, config)
+	viewsMap[ViewOutdated] = views.NewOutdatedView(brewClient, appState, config)
+	viewsMap[ViewTaps] = views.NewTapsView(brewClient, appState, config)
 	viewsMap[ViewDiagnostics] = views.NewDiagnosticsView(brewClient, appState)
+	viewsMap[ViewVulnerabilities] = views.NewVulnerabilitiesView(brewClient, appState, config)
+	viewsMap[ViewLicenses] = views.NewLicensesView(brewClient, appState, config)
+	viewsMap[ViewPolicy] = views.NewPolicyView(brewClient, appState, config)
+	viewsMap[ViewDrift] = views.NewDriftView(brewClient, appState, config)
+	viewsMap[ViewDebug] = views.NewDebugView()
 
 	// Initialize spinner for loading screen
 	s := spinner.New()
@@ -96,27 +127,43 @@ func New() *Model {
 		brewClient:  brewClient,
 		state:       appState,
 		config:      config,
+		vulns:       newVulnerabilityChecker(state.ExpandHome(config.OSVPath), config.OSVEcosystems),
+		actions:     newActionDispatcher(brewClient, config),
 		header:      components.NewHeader(),
 		statusBar:   components.NewStatusBar(),
-		dialog:      components.NewConfirmDialog("", ""),
+		palette:     components.NewPalette(),
+		cmdline:     components.NewCommandLine(),
 		spinner:     s,
 		currentView: ViewHome,
 		viewStack:   []ViewType{},
 		views:       viewsMap,
 		ready:       false,
+		err:         configErr,
 	}
 }
 
 // Init initializes the application
 func (m Model) Init() tea.Cmd {
+	// Don't run anything, like actions the policy would refuse, with the
+	// defaults in place of a config that failed to load
+	if m.err != nil {
+		return nil
+	}
+
 	var cmds []tea.Cmd
 
 	// Load packages
 	cmds = append(cmds,
 		loadInstalledPackages(m.brewClient),
-		loadOutdatedPackages(m.brewClient),
+		loadOutdatedPackages(m.brewClient, m.config.GreedyCasks),
+		loadDiskUsage(m.brewClient),
 		m.spinner.Tick,
+		scheduleUpdateCheck(m.config.UpdateInterval),
 	)
+	if m.config.AutoUpdateOnStartup {
+		// Init can't mark the check as running, Update starts it
+		cmds = append(cmds, func() tea.Msg { return updateCheckMsg{} })
+	}
 
 	// Initialize the home view (dashboard)
 	if view, ok := m.views[ViewHome]; ok {
@@ -132,6 +179,20 @@ func (m Model) Init() tea.Cmd {
 
 // Update handles messages
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.err != nil {
+		switch msg := msg.(type) {
+		case tea.WindowSizeMsg:
+			m.width = msg.Width
+			m.height = msg.Height
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "q", "esc", "ctrl+c":
+				return m, tea.Quit
+			}
+		}
+		return m, nil
+	}
+
 	var cmds []tea.Cmd
 
 	// Handle spinner ticks while loading
@@ -143,17 +204,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// Handle dialog updates first
-	if m.dialog.IsVisible() {
-		var cmd tea.Cmd
-		m.dialog, cmd = m.dialog.Update(msg)
-		if cmd != nil {
-			cmds = append(cmds, cmd)
+	// The result of the last command shows until the next key
+	if _, ok := msg.(tea.KeyMsg); ok && m.cmdline.HasMessage() {
+		m.cmdline.ClearMessage()
+	}
+
+	// The command line, palette and action dialogs take all input while
+	// open, other messages carry on
+	if m.cmdline.IsVisible() {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			var cmd tea.Cmd
+			m.cmdline, cmd = m.cmdline.Update(msg)
+			return m, cmd
+		}
+	}
+	if m.palette.IsVisible() {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			var cmd tea.Cmd
+			m.palette, cmd = m.palette.Update(msg)
+			return m, cmd
+		}
+	}
+	if m.actions.visible() {
+		switch msg.(type) {
+		case tea.KeyMsg, tea.MouseMsg:
+			return m, m.actions.update(msg)
 		}
+	}
 
-		// If dialog is still visible, don't process other updates
-		if m.dialog.IsVisible() {
-			return m, tea.Batch(cmds...)
+	// Views that don't handle the mouse themselves scroll with the wheel as
+	// with the arrow keys
+	if mouse, ok := msg.(tea.MouseMsg); ok {
+		if v, ok := m.views[m.currentView].(interface{ HandlesMouse() bool }); !ok || !v.HandlesMouse() {
+			switch mouse.Button {
+			case tea.MouseButtonWheelUp:
+				msg = tea.KeyMsg{Type: tea.KeyUp}
+			case tea.MouseButtonWheelDown:
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			}
 		}
 	}
 
@@ -168,11 +257,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				v.SetSize(m.width, m.height)
 			}
 		}
+		m.palette.SetSize(m.width, m.height)
+		m.cmdline.SetWidth(m.width)
 
 		m.ready = true
 		return m, nil
 
 	case tea.KeyMsg:
+		// Views capturing text input get every key except ctrl+c
+		if v, ok := m.views[m.currentView].(interface{ CapturingInput() bool }); ok && v.CapturingInput() && msg.String() != "ctrl+c" {
+			break
+		}
+
 		// Global key bindings
 		switch msg.String() {
 		case "ctrl+c", "q":
@@ -200,18 +296,58 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg { return NavigateMsg(ViewTaps) }
 		case "6":
 			return m, func() tea.Msg { return NavigateMsg(ViewDiagnostics) }
+		case "7":
+			return m, func() tea.Msg { return NavigateMsg(ViewVulnerabilities) }
+		case "8":
+			return m, func() tea.Msg { return NavigateMsg(ViewLicenses) }
+		case "9":
+			return m, func() tea.Msg { return NavigateMsg(ViewPolicy) }
+		case "0":
+			return m, func() tea.Msg { return NavigateMsg(ViewDrift) }
+		case "ctrl+d":
+			return m, func() tea.Msg { return NavigateMsg(ViewDebug) }
+		case "ctrl+p":
+			cmd := m.openPalette()
+			return m, cmd
+		case ":":
+			cmd := m.openCommandLine()
+			return m, cmd
 		}
 
-	case NavigateMsg:
-		m.viewStack = append(m.viewStack, m.currentView)
-		m.currentView = ViewType(msg)
-		m.state.ClearMessages()
-		if view, ok := m.views[m.currentView]; ok {
-			if v, ok := view.(interface{ Init() tea.Cmd }); ok {
-				return m, v.Init()
-			}
+	case components.CommandLineMsg:
+		cmd := m.runCommandLine(msg.Line)
+		return m, cmd
+
+	case brewOutputMsg:
+		return m, tea.Batch(
+			m.notifyViews(views.ActionOutputMsg{Op: msg.op, Line: msg.line}),
+			func() tea.Msg { return readBrewOutput(msg.op, msg.output, msg.errs) },
+		)
+
+	case brewFinishedMsg:
+		// Arbitrary commands may change packages even when they fail
+		finished := views.ActionFinishedMsg{Op: msg.op, Msg: "Finished " + msg.op, Err: msg.err}
+		cmds = append(cmds, m.actionFinished())
+		if msg.err != nil {
+			m.state.SetError(msg.err)
+			m.cmdline.SetMessage(msg.op+": "+msg.err.Error(), true)
+		} else {
+			m.state.SetSuccess(finished.Msg)
+			m.cmdline.SetMessage("✓ "+finished.Msg, false)
 		}
-		return m, nil
+		cmds = append(cmds, m.notifyViews(finished), func() tea.Msg { return views.RefreshPackagesMsg{} })
+		return m, tea.Batch(cmds...)
+
+	case components.PaletteMsg:
+		if msg.Index >= len(m.paletteEntries) {
+			return m, nil
+		}
+		cmd := m.paletteEntries[msg.Index].run(&m)
+		return m, cmd
+
+	case NavigateMsg:
+		cmd := m.navigate(ViewType(msg))
+		return m, cmd
 
 	case BackMsg:
 		if len(m.viewStack) > 0 {
@@ -231,6 +367,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case PackagesLoadedMsg:
 		m.state.SetInstalled(msg.Packages)
+		if cmd := m.vulns.check(msg.Packages, false); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		if view, ok := m.views[m.currentView]; ok {
 			viewMsg := views.PackagesLoadedMsg{Packages: msg.Packages}
 			updatedView, cmd := view.Update(viewMsg)
@@ -242,7 +381,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 
 	case OutdatedLoadedMsg:
-		m.state.SetOutdated(msg.Packages)
+		if msg.Err != nil {
+			m.state.ClearOutdated()
+		} else {
+			m.state.SetOutdated(msg.Packages)
+		}
 		if view, ok := m.views[m.currentView]; ok {
 			viewMsg := views.OutdatedLoadedMsg{Packages: msg.Packages}
 			updatedView, cmd := view.Update(viewMsg)
@@ -253,16 +396,105 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 
+	case updateTickMsg:
+		return m, tea.Batch(m.startUpdateCheck(), scheduleUpdateCheck(m.config.UpdateInterval))
+
+	case updateCheckMsg:
+		return m, m.startUpdateCheck()
+
+	case updateCheckedMsg:
+		m.checkingUpdates = false
+		if msg.Err != nil {
+			// Try again at the next tick rather than interrupting the user
+			return m, nil
+		}
+		m.state.SetOutdated(msg.Packages)
+		if view, ok := m.views[m.currentView]; ok {
+			viewMsg := views.OutdatedLoadedMsg{Packages: msg.Packages, Background: true}
+			updatedView, cmd := view.Update(viewMsg)
+			m.views[m.currentView] = updatedView
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		return m, tea.Batch(cmds...)
+
+	case VulnerabilitiesLoadedMsg:
+		if msg.Err != nil {
+			m.state.SetError(fmt.Errorf("loading OSV data: %w", msg.Err))
+		} else {
+			m.state.SetVulnerabilities(msg.Findings)
+		}
+		if view, ok := m.views[m.currentView]; ok {
+			viewMsg := views.VulnerabilitiesLoadedMsg{Findings: msg.Findings, Err: msg.Err}
+			updatedView, cmd := view.Update(viewMsg)
+			m.views[m.currentView] = updatedView
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		return m, tea.Batch(cmds...)
+
 	case TapsLoadedMsg:
 		m.state.Taps = msg.Taps
 		return m, nil
+
+	case DiskUsageLoadedMsg:
+		m.state.SetDiskUsage(msg.Usage)
+		if view, ok := m.views[m.currentView]; ok {
+			updatedView, cmd := view.Update(views.DiskUsageLoadedMsg{Usage: msg.Usage})
+			m.views[m.currentView] = updatedView
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		return m, tea.Batch(cmds...)
 	}
 
-	switch msg.(type) {
+	if cmd, ok := m.actions.handle(msg); ok {
+		return m, cmd
+	}
+
+	switch msg := msg.(type) {
+	case views.ActionStartedMsg:
+		m.actionsRunning++
+		return m, m.notifyViews(msg)
+
+	case views.ActionFinishedMsg:
+		check := m.actionFinished()
+		saveErr := m.actions.finished(msg)
+		if saveErr != nil {
+			msg.Logs = append(msg.Logs, "Error: "+saveErr.Error())
+		}
+		if msg.Err != nil {
+			m.state.SetError(msg.Err)
+			return m, tea.Batch(m.notifyViews(msg), check)
+		}
+		if saveErr != nil {
+			m.state.SetError(saveErr)
+		} else {
+			m.state.SetSuccess(msg.Msg)
+		}
+		return m, tea.Batch(m.notifyViews(msg), func() tea.Msg { return views.RefreshPackagesMsg{} }, check)
+
+	case views.PolicyBlockedMsg:
+		m.actions.block(msg.Reason)
+		return m, m.notifyViews(msg)
+
+	case views.RequestBrewMsg:
+		return m, m.runBrew(msg.Args)
+
+	case views.RefreshOutdatedMsg:
+		return m, loadOutdatedPackages(m.brewClient, m.config.GreedyCasks)
+
+	case views.RefreshVulnerabilitiesMsg:
+		return m, m.vulns.check(m.state.GetInstalledPackages(), true)
+
 	case views.RefreshPackagesMsg:
 		return m, tea.Batch(
 			loadInstalledPackages(m.brewClient),
-			loadOutdatedPackages(m.brewClient),
+			loadOutdatedPackages(m.brewClient, m.config.GreedyCasks),
+			loadDiskUsage(m.brewClient),
 		)
 	}
 
@@ -296,6 +528,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the application
 func (m Model) View() string {
+	if m.err != nil {
+		return lipgloss.NewStyle().Padding(2, 4).Width(m.width).Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			styles.ErrorStyle.Render("Failed to load the config: "+m.err.Error()),
+			"",
+			styles.DimStyle.Render("Fix or remove the config file and start brewst again."),
+			styles.DimStyle.Render("Press q to quit."),
+		))
+	}
+
 	if !m.ready {
 		loadingStyle := lipgloss.NewStyle().
 			Padding(2, 4).
@@ -328,8 +570,12 @@ func (m Model) View() string {
 		content = "View not implemented yet"
 	}
 
-	if m.dialog.IsVisible() {
-		content = m.dialog.Overlay(content, m.width, m.height)
+	content = m.cmdline.Overlay(content)
+	if m.actions.visible() {
+		content = m.actions.overlay(content, m.width, m.height)
+	}
+	if m.palette.IsVisible() {
+		content = m.palette.Overlay(content, m.width, m.height)
 	}
 
 	return content
@@ -337,6 +583,36 @@ func (m Model) View() string {
 
 // Helper functions
 
+// navigate switches to a view, remembering the current one for going back
+func (m *Model) navigate(view ViewType) tea.Cmd {
+	m.viewStack = append(m.viewStack, m.currentView)
+	m.currentView = view
+	m.state.ClearMessages()
+	if v, ok := m.views[m.currentView].(interface{ Init() tea.Cmd }); ok {
+		return v.Init()
+	}
+	return nil
+}
+
+// notifyViews sends an action's progress to the dashboard, which keeps the
+// session log, and to the current view
+func (m Model) notifyViews(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, viewType := range []ViewType{ViewHome, m.currentView} {
+		view, ok := m.views[viewType]
+		if !ok {
+			continue
+		}
+		updatedView, cmd := view.Update(msg)
+		m.views[viewType] = updatedView
+		cmds = append(cmds, cmd)
+		if m.currentView == ViewHome {
+			break
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
 func (m *Model) getViewName(view ViewType) string {
 	switch view {
 	case ViewHome:
@@ -353,6 +629,16 @@ func (m *Model) getViewName(view ViewType) string {
 		return "Taps"
 	case ViewDiagnostics:
 		return "Diagnostics"
+	case ViewVulnerabilities:
+		return "Vulnerabilities"
+	case ViewLicenses:
+		return "Licenses"
+	case ViewPolicy:
+		return "Policy"
+	case ViewDrift:
+		return "Team Manifest"
+	case ViewDebug:
+		return "Debug"
 	default:
 		return "Unknown"
 	}
@@ -372,12 +658,27 @@ func (m *Model) getStatusText() string {
 	installed := m.state.GetInstalledCount()
 	outdated := m.state.GetOutdatedCount()
 
+	if newUpdates := m.state.GetNewUpdates(); newUpdates > 0 {
+		return fmt.Sprintf("Installed: %d | Outdated: %d | %s | Press ? for help", installed, outdated, views.NewUpdatesText(newUpdates))
+	}
+
 	if outdated > 0 {
 		return fmt.Sprintf("Installed: %d | Outdated: %d | Press ? for help", installed, outdated)
 	}
 	return fmt.Sprintf("Installed: %d | Press ? for help", installed)
 }
 
+func startTracing(config *state.Config) error {
+	path, err := config.GetTracePath()
+	if err != nil {
+		return fmt.Errorf("debug trace: %w", err)
+	}
+	if err := brew.StartTracing(path); err != nil {
+		return fmt.Errorf("debug trace: %w", err)
+	}
+	return nil
+}
+
 func loadInstalledPackages(client brew.Client) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
@@ -389,13 +690,40 @@ func loadInstalledPackages(client brew.Client) tea.Cmd {
 	}
 }
 
-func loadOutdatedPackages(client brew.Client) tea.Cmd {
+// startUpdateCheck runs a background update check unless one is running,
+// deferring it while actions run
+func (m *Model) startUpdateCheck() tea.Cmd {
+	if m.checkingUpdates {
+		return nil
+	}
+	if m.actionsRunning > 0 {
+		// brew update would wait for the running action's lock
+		m.deferredCheck = true
+		return nil
+	}
+	m.checkingUpdates = true
+	return checkForUpdates(m.brewClient, m.config.GreedyCasks)
+}
+
+// actionFinished counts down the running actions, running an update check
+// that was deferred while they ran
+func (m *Model) actionFinished() tea.Cmd {
+	m.actionsRunning = max(m.actionsRunning-1, 0)
+	if m.actionsRunning > 0 || !m.deferredCheck || m.checkingUpdates {
+		return nil
+	}
+	m.deferredCheck = false
+	m.checkingUpdates = true
+	return checkForUpdates(m.brewClient, m.config.GreedyCasks)
+}
+
+func loadOutdatedPackages(client brew.Client, greedy bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		outdated, err := client.Outdated(ctx)
+		outdated, err := client.Outdated(ctx, greedy)
 		if err != nil {
-			// Don't return error, just empty list
-			return OutdatedLoadedMsg{Packages: []brew.OutdatedPackage{}}
+			// Show an empty list rather than an error
+			return OutdatedLoadedMsg{Packages: []brew.OutdatedPackage{}, Err: err}
 		}
 		return OutdatedLoadedMsg{Packages: outdated}
 	}
@@ -411,3 +739,14 @@ func loadTaps(client brew.Client) tea.Cmd {
 		return TapsLoadedMsg{Taps: taps}
 	}
 }
+
+func loadDiskUsage(client brew.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		usage, err := client.DiskUsage(ctx)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return DiskUsageLoadedMsg{Usage: usage}
+	}
+}
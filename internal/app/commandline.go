@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/views"
+)
+
+// nativeCommands are the command line's own commands, besides the commands
+// the views offer in the palette
+var nativeCommands = []string{
+	"brew", "install", "reinstall", "uninstall", "upgrade", "pin", "unpin",
+	"info", "search", "filter", "quit",
+}
+
+// brewOutputMsg carries a line of a streamed brew command and the channels
+// to read the rest from
+type brewOutputMsg struct {
+	op     string
+	line   string
+	output <-chan string
+	errs   <-chan error
+}
+
+// brewFinishedMsg is sent when a streamed brew command exits
+type brewFinishedMsg struct {
+	op  string
+	err error
+}
+
+// openCommandLine opens the ":" prompt, completing command names and the
+// installed packages
+func (m *Model) openCommandLine() tea.Cmd {
+	commands := append([]string{}, nativeCommands...)
+	for _, viewType := range m.commandViews() {
+		if view, ok := m.views[viewType].(interface{ Commands() []views.Command }); ok {
+			for _, command := range view.Commands() {
+				commands = append(commands, command.Name)
+			}
+		}
+	}
+	var names []string
+	for _, pkg := range m.state.GetInstalledPackages() {
+		names = append(names, pkg.Name)
+	}
+	m.cmdline.SetCompletions(commands, names)
+	return m.cmdline.Show()
+}
+
+// commandViews lists the views to look for commands in, the current view first
+func (m *Model) commandViews() []ViewType {
+	order := []ViewType{m.currentView}
+	for _, vk := range viewKeys {
+		if vk.view != m.currentView {
+			order = append(order, vk.view)
+		}
+	}
+	return order
+}
+
+// runCommandLine runs a line entered at the ":" prompt
+func (m *Model) runCommandLine(line string) tea.Cmd {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "brew":
+		if len(args) == 0 {
+			return m.commandError("usage: brew <command> [args...]")
+		}
+		return m.runBrew(args)
+
+	case "install":
+		cask := false
+		var names []string
+		for _, arg := range args {
+			if arg == "--cask" {
+				cask = true
+			} else if arg != "--formula" {
+				names = append(names, arg)
+			}
+		}
+		if len(names) != 1 {
+			return m.commandError("usage: install [--cask|--formula] <package>")
+		}
+		pkg := brew.Package{Name: names[0], Type: brew.TypeFormula}
+		if cask {
+			pkg.Type = brew.TypeCask
+		}
+		if installed, ok := m.installedPackage(names[0]); ok {
+			pkg = installed
+		}
+		return func() tea.Msg { return views.RequestInstallMsg{Package: pkg} }
+
+	case "reinstall", "uninstall", "pin", "unpin", "info":
+		if len(args) != 1 {
+			return m.commandError(fmt.Sprintf("usage: %s <package>", name))
+		}
+		pkg, ok := m.installedPackage(args[0])
+		if !ok {
+			return m.commandError(args[0] + " is not installed")
+		}
+		switch name {
+		case "reinstall":
+			return func() tea.Msg { return views.RequestReinstallMsg{Package: pkg} }
+		case "uninstall":
+			return func() tea.Msg { return views.RequestUninstallMsg{Package: pkg} }
+		case "info":
+			m.state.SetSelectedPackage(&pkg)
+			return m.navigate(ViewDetails)
+		}
+		if pkg.Pinned == (name == "pin") {
+			return m.commandError(fmt.Sprintf("%s is already %sned", pkg.Name, name))
+		}
+		return func() tea.Msg { return views.TogglePinMsg{PackageName: pkg.Name, Pinned: pkg.Pinned} }
+
+	case "upgrade":
+		if len(args) == 0 {
+			return m.runViewCommand(ViewHome, views.CommandMsg{Name: "upgrade-all"})
+		}
+		outdated := make(map[string]brew.OutdatedPackage)
+		for _, pkg := range m.state.GetOutdatedPackages() {
+			outdated[pkg.Name] = pkg
+		}
+		var packages []brew.OutdatedPackage
+		for _, arg := range args {
+			pkg, ok := outdated[arg]
+			if !ok {
+				return m.commandError(arg + " is up to date")
+			}
+			packages = append(packages, pkg)
+		}
+		return func() tea.Msg { return views.RequestUpgradeMsg{Packages: packages} }
+
+	case "search", "filter":
+		// The query keeps its own spacing, filters can combine terms
+		query := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), name))
+		if name == "search" && query == "" {
+			return m.commandError("usage: search <query>")
+		}
+		return m.runViewCommand(ViewHome, views.CommandMsg{Name: name, Arg: query})
+
+	case "q", "quit":
+		_ = state.SaveFavorites(m.state.Favorites)
+		return tea.Quit
+	}
+
+	// Commands the views offer in the palette, the current view's first
+	for _, viewType := range m.commandViews() {
+		view, ok := m.views[viewType].(interface{ Commands() []views.Command })
+		if !ok {
+			continue
+		}
+		for _, command := range view.Commands() {
+			if command.Name == name {
+				return m.runViewCommand(viewType, views.CommandMsg{Name: name, Arg: strings.Join(args, " ")})
+			}
+		}
+	}
+	return m.commandError("Unknown command: " + name)
+}
+
+// commandError shows why a command couldn't run on the command line
+func (m *Model) commandError(message string) tea.Cmd {
+	m.cmdline.SetMessage(message, true)
+	return nil
+}
+
+// installedPackage looks up an installed package by name
+func (m *Model) installedPackage(name string) (brew.Package, bool) {
+	for _, pkg := range m.state.GetInstalledPackages() {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return brew.Package{}, false
+}
+
+// runBrew runs a brew command as typed. Commands that change packages or taps
+// go through the same requests as the views, so the policies, the license
+// prompt and the hooks apply to them too. Other commands are streamed.
+func (m *Model) runBrew(args []string) tea.Cmd {
+	name := args[0]
+	switch name {
+	case "install", "reinstall", "uninstall", "remove", "rm", "upgrade":
+		if name == "remove" || name == "rm" {
+			name = "uninstall"
+		}
+		command := []string{name}
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "--cask" || arg == "--casks":
+				command = append(command, "--cask")
+			case arg == "--formula" || arg == "--formulae":
+				command = append(command, "--formula")
+			case strings.HasPrefix(arg, "-"):
+				return m.commandError(fmt.Sprintf("brew %s %s: only --cask and --formula work here, choose other options in the install dialog", name, arg))
+			default:
+				command = append(command, arg)
+			}
+		}
+		if name != "install" {
+			// Installed packages are looked up by name
+			command = slices.DeleteFunc(command, func(arg string) bool { return strings.HasPrefix(arg, "-") })
+		}
+		return m.runCommandLine(strings.Join(command, " "))
+
+	case "tap":
+		if len(args) == 1 {
+			// Lists the taps
+			return m.streamBrew(args)
+		}
+		if len(args) != 2 || strings.Count(args[1], "/") != 1 || strings.HasPrefix(args[1], "-") {
+			return m.commandError("usage: brew tap [user/repo]")
+		}
+		return func() tea.Msg { return views.RequestTapMsg{Tap: args[1]} }
+
+	case "autoremove":
+		for _, arg := range args[1:] {
+			if arg != "--dry-run" && arg != "-n" {
+				return m.commandError("usage: brew autoremove [--dry-run]")
+			}
+		}
+		if len(args) == 1 {
+			return func() tea.Msg { return views.RequestAutoremoveMsg{All: true} }
+		}
+	}
+	return m.streamBrew(args)
+}
+
+// streamBrew runs a brew command, sending its output to the logs line by line
+func (m *Model) streamBrew(args []string) tea.Cmd {
+	op := "brew " + strings.Join(args, " ")
+	client := m.brewClient
+	started := func() tea.Msg {
+		return views.ActionStartedMsg{Op: op, Message: "Running " + op + "..."}
+	}
+	return tea.Sequence(started, func() tea.Msg {
+		output, errs := client.Stream(context.Background(), args)
+		return readBrewOutput(op, output, errs)
+	})
+}
+
+// readBrewOutput waits for the next line of a streamed brew command, or for
+// the command to exit
+func readBrewOutput(op string, output <-chan string, errs <-chan error) tea.Msg {
+	line, ok := <-output
+	if !ok {
+		return brewFinishedMsg{op: op, err: <-errs}
+	}
+	return brewOutputMsg{op: op, line: line, output: output, errs: errs}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/license"
+	"github.com/lazar0169/brewst/internal/ui/components"
+)
+
+// installFlags are the brew install flags offered for every formula
+var installFlags = []struct {
+	flag        string
+	description string
+	head        bool // Only for formulae with a HEAD spec
+}{
+	{"--HEAD", "Install the development version", true},
+	{"--build-from-source", "Compile from source even if a bottle is available", false},
+	{"--force-bottle", "Pour a bottle even if it would not normally be used", false},
+	{"--ignore-dependencies", "Skip installing dependencies (may break the formula)", false},
+	{"--skip-post-install", "Skip the formula's post-install steps", false},
+}
+
+// installLookupMsg carries what brew knows about a name before installing it.
+// A name can be both a formula and a cask.
+type installLookupMsg struct {
+	pkg     brew.Package
+	formula *brew.PackageInfo // Nil when there is no such formula
+	cask    *brew.PackageInfo // Nil when there is no such cask
+}
+
+// pendingInstall is an install waiting for its options
+type pendingInstall struct {
+	packages []brew.Package // Package of each choice in the options dialog
+}
+
+// lookupInstall looks a name up as a formula and as a cask
+func (d *actionDispatcher) lookupInstall(pkg brew.Package) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		msg := installLookupMsg{pkg: pkg}
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			msg.formula, _ = d.client.Info(ctx, pkg.Name, false)
+		}()
+		go func() {
+			defer wg.Done()
+			msg.cask, _ = d.client.Info(ctx, pkg.Name, true)
+		}()
+		wg.Wait()
+		return msg
+	}
+}
+
+// showInstallOptions asks how to install a name, with the options it was
+// installed with last time checked
+func (d *actionDispatcher) showInstallOptions(msg installLookupMsg) {
+	remembered := d.config.InstallOptions[msg.pkg.Name]
+
+	var choices []components.OptionsChoice
+	pending := &pendingInstall{}
+	selected := 0
+	for _, info := range []*brew.PackageInfo{msg.formula, msg.cask} {
+		if info == nil {
+			continue
+		}
+		pkg := msg.pkg
+		pkg.Type = info.Type
+		if info.Type == msg.pkg.Type {
+			selected = len(choices)
+		}
+		choices = append(choices, d.installChoice(pkg, info, remembered))
+		pending.packages = append(pending.packages, pkg)
+	}
+	if len(choices) == 0 {
+		// brew couldn't tell, install what was asked for
+		choices = append(choices, d.installChoice(msg.pkg, nil, remembered))
+		pending.packages = append(pending.packages, msg.pkg)
+	}
+
+	d.install = pending
+	d.options.Show(choices, selected)
+}
+
+// installChoice describes installing pkg, warning when the license policy
+// disallows its license
+func (d *actionDispatcher) installChoice(pkg brew.Package, info *brew.PackageInfo, remembered brew.InstallOptions) components.OptionsChoice {
+	choice := components.OptionsChoice{
+		Label:   "Formula",
+		Message: fmt.Sprintf("Install %s?", pkg.Name),
+	}
+	if pkg.Type == brew.TypeCask {
+		choice.Label = "Cask"
+	}
+	if info != nil && info.Description != "" {
+		choice.Message = fmt.Sprintf("Install %s? %s", pkg.Name, info.Description)
+	}
+
+	policy := d.config.LicensePolicy
+	if info != nil && policy.Enabled() {
+		if violations := policy.Violations(info.License); len(violations) > 0 {
+			choice.Message = fmt.Sprintf("⚠ %s is licensed under %s, which the license policy disallows (%s).\n\nInstall anyway?",
+				pkg.Name, license.Normalize(info.License), strings.Join(violations, ", "))
+		}
+	}
+
+	// Casks install as they are
+	if pkg.Type == brew.TypeCask {
+		return choice
+	}
+	for _, flag := range installFlags {
+		if flag.head && info != nil && !info.HasHead {
+			continue
+		}
+		choice.Options = append(choice.Options, components.ChecklistItem{
+			Label:   flag.flag,
+			Detail:  flag.description,
+			Checked: slices.Contains(installArgsFlags(remembered), flag.flag),
+		})
+	}
+	if info != nil {
+		for _, option := range info.Options {
+			choice.Options = append(choice.Options, components.ChecklistItem{
+				Label:   option.Flag,
+				Detail:  option.Description,
+				Checked: slices.Contains(remembered.Flags, option.Flag),
+			})
+		}
+	}
+	return choice
+}
+
+// installArgsFlags returns the flags brew install gets for opts
+func installArgsFlags(opts brew.InstallOptions) []string {
+	return brew.InstallArgs("", opts)[2:]
+}
+
+// installOptions turns the checked options of the dialog into brew options
+func installOptions(pkg brew.Package, checked []components.ChecklistItem) brew.InstallOptions {
+	opts := brew.InstallOptions{Cask: pkg.Type == brew.TypeCask}
+	for _, item := range checked {
+		switch item.Label {
+		case "--HEAD":
+			opts.HEAD = true
+		case "--build-from-source":
+			opts.BuildFromSource = true
+		case "--force-bottle":
+			opts.ForceBottle = true
+		case "--ignore-dependencies":
+			opts.IgnoreDependencies = true
+		case "--skip-post-install":
+			opts.SkipPostInstall = true
+		default:
+			opts.Flags = append(opts.Flags, item.Label)
+		}
+	}
+	return opts
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/components"
+	"github.com/lazar0169/brewst/internal/ui/views"
+)
+
+// viewKeys are the views reachable from the palette, with their global keys
+var viewKeys = []struct {
+	view ViewType
+	keys string
+}{
+	{ViewHome, "1"},
+	{ViewInstalled, "2"},
+	{ViewSearch, "3"},
+	{ViewOutdated, "4"},
+	{ViewTaps, "5"},
+	{ViewDiagnostics, "6"},
+	{ViewVulnerabilities, "7"},
+	{ViewLicenses, "8"},
+	{ViewPolicy, "9"},
+	{ViewDrift, "0"},
+	{ViewDebug, "ctrl+d"},
+}
+
+// paletteEntry is a palette item and what choosing it does
+type paletteEntry struct {
+	item components.PaletteItem
+	run  func(m *Model) tea.Cmd
+}
+
+// openPalette opens the command palette with the views' actions, navigation,
+// recent searches and installed packages
+func (m *Model) openPalette() tea.Cmd {
+	m.paletteEntries = m.buildPaletteEntries()
+	items := make([]components.PaletteItem, len(m.paletteEntries))
+	for i, entry := range m.paletteEntries {
+		items[i] = entry.item
+	}
+	return m.palette.Show(items)
+}
+
+func (m *Model) buildPaletteEntries() []paletteEntry {
+	var entries []paletteEntry
+
+	// Actions of the current view come first. Views offering the same action
+	// list it once.
+	seen := make(map[string]bool)
+	order := []ViewType{m.currentView}
+	for _, vk := range viewKeys {
+		order = append(order, vk.view)
+	}
+	for _, viewType := range order {
+		view, ok := m.views[viewType].(interface{ Commands() []views.Command })
+		if !ok {
+			continue
+		}
+		for _, command := range view.Commands() {
+			if seen[command.Title] {
+				continue
+			}
+			seen[command.Title] = true
+			viewType, msg := viewType, views.CommandMsg{Name: command.Name}
+			entries = append(entries, paletteEntry{
+				item: components.PaletteItem{Title: command.Title, Kind: m.getViewName(viewType), Keys: command.Keys},
+				run:  func(m *Model) tea.Cmd { return m.runViewCommand(viewType, msg) },
+			})
+		}
+	}
+
+	for _, vk := range viewKeys {
+		viewType := vk.view
+		entries = append(entries, paletteEntry{
+			item: components.PaletteItem{Title: "Go to " + m.getViewName(viewType), Kind: "View", Keys: vk.keys},
+			run:  func(m *Model) tea.Cmd { return m.navigate(viewType) },
+		})
+	}
+	entries = append(entries, paletteEntry{
+		item: components.PaletteItem{Title: "Quit", Kind: "App", Keys: "q"},
+		run: func(m *Model) tea.Cmd {
+			_ = state.SaveFavorites(m.state.Favorites)
+			return tea.Quit
+		},
+	})
+
+	for _, query := range m.state.GetRecentSearches() {
+		msg := views.CommandMsg{Name: "search", Arg: query}
+		entries = append(entries, paletteEntry{
+			item: components.PaletteItem{Title: query, Kind: "Recent search"},
+			run:  func(m *Model) tea.Cmd { return m.runViewCommand(ViewHome, msg) },
+		})
+	}
+
+	for _, pkg := range m.state.GetInstalledPackages() {
+		kind := "Formula"
+		if pkg.Type == brew.TypeCask {
+			kind = "Cask"
+		}
+		pkg := pkg
+		entries = append(entries, paletteEntry{
+			item: components.PaletteItem{Title: pkg.Name, Kind: kind + " " + pkg.Version},
+			run: func(m *Model) tea.Cmd {
+				m.state.SetSelectedPackage(&pkg)
+				return m.navigate(ViewDetails)
+			},
+		})
+	}
+	return entries
+}
+
+// runViewCommand switches to a view, if it isn't the current one, and runs
+// one of its commands
+func (m *Model) runViewCommand(viewType ViewType, msg views.CommandMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	if m.currentView != viewType {
+		cmds = append(cmds, m.navigate(viewType))
+	}
+	if view, ok := m.views[viewType]; ok {
+		updatedView, cmd := view.Update(msg)
+		m.views[viewType] = updatedView
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
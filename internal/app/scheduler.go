@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lazar0169/brewst/internal/brew"
+)
+
+// Background update checks. While brewst is open it periodically runs
+// brew update and reloads the outdated list without touching the UI state.
+
+// updateTickMsg is sent when a background update check is due
+type updateTickMsg struct{}
+
+// updateCheckMsg asks for an update check now, without scheduling the next
+type updateCheckMsg struct{}
+
+// updateCheckedMsg carries the result of a background update check
+type updateCheckedMsg struct {
+	Packages []brew.OutdatedPackage
+	Err      error
+}
+
+// scheduleUpdateCheck schedules the next background check, interval in seconds
+func scheduleUpdateCheck(interval int) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(time.Duration(interval)*time.Second, func(time.Time) tea.Msg {
+		return updateTickMsg{}
+	})
+}
+
+// checkForUpdates updates Homebrew and fetches the outdated packages
+func checkForUpdates(client brew.Client, greedy bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := client.Update(ctx); err != nil {
+			return updateCheckedMsg{Err: err}
+		}
+		outdated, err := client.Outdated(ctx, greedy)
+		if err != nil {
+			return updateCheckedMsg{Err: err}
+		}
+		return updateCheckedMsg{Packages: outdated}
+	}
+}
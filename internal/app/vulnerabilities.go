@@ -0,0 +1,49 @@
+package app
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/vuln"
+)
+
+// VulnerabilitiesLoadedMsg carries the vulnerabilities found in installed packages
+type VulnerabilitiesLoadedMsg struct {
+	Findings []vuln.Finding
+	Err      error
+}
+
+// vulnerabilityChecker loads the OSV database once and matches packages against it
+type vulnerabilityChecker struct {
+	path       string
+	ecosystems []string
+
+	mu sync.Mutex
+	db *vuln.Database
+}
+
+func newVulnerabilityChecker(path string, ecosystems []string) *vulnerabilityChecker {
+	return &vulnerabilityChecker{path: path, ecosystems: ecosystems}
+}
+
+// check matches packages against the database, loading it first if needed.
+// With reload the database is read from disk again.
+func (c *vulnerabilityChecker) check(packages []brew.Package, reload bool) tea.Cmd {
+	if c.path == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.db == nil || reload {
+			db, err := vuln.Load(c.path, c.ecosystems)
+			if err != nil {
+				return VulnerabilitiesLoadedMsg{Err: err}
+			}
+			c.db = db
+		}
+		return VulnerabilitiesLoadedMsg{Findings: c.db.Check(packages)}
+	}
+}
@@ -2,6 +2,13 @@ package brew
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Client defines the interface for interacting with Homebrew
@@ -21,14 +28,30 @@ type Client interface {
 	// Uninstall uninstalls a package
 	Uninstall(ctx context.Context, name string, opts UninstallOptions) error
 
+	// Reinstall uninstalls and installs a package again
+	Reinstall(ctx context.Context, name string, opts InstallOptions) error
+
+	// Link symlinks a formula's files into the prefix. A dry run returns the
+	// files it would link.
+	Link(ctx context.Context, name string, opts LinkOptions) (string, error)
+
+	// Unlink removes a formula's symlinks from the prefix
+	Unlink(ctx context.Context, name string) error
+
+	// SwitchVersion links another installed version of a formula in place of
+	// the linked one. If that fails, the opt link points at the previous
+	// version again, which is relinked if it was linked.
+	SwitchVersion(ctx context.Context, name, version string) error
+
 	// Update updates Homebrew
 	Update(ctx context.Context) error
 
-	// Upgrade upgrades packages
-	Upgrade(ctx context.Context, packages []string) error
+	// Upgrade upgrades packages, all outdated packages when none are given
+	Upgrade(ctx context.Context, packages []string, opts UpgradeOptions) error
 
-	// Outdated returns packages that have updates available
-	Outdated(ctx context.Context) ([]OutdatedPackage, error)
+	// Outdated returns packages that have updates available. With greedy it
+	// also returns casks that update themselves, marked AutoUpdates.
+	Outdated(ctx context.Context, greedy bool) ([]OutdatedPackage, error)
 
 	// Pin pins a package to prevent updates
 	Pin(ctx context.Context, name string) error
@@ -39,6 +62,16 @@ type Client interface {
 	// Doctor runs brew doctor diagnostics
 	Doctor(ctx context.Context) (string, error)
 
+	// Diagnose runs brew doctor and parses the warnings it reports
+	Diagnose(ctx context.Context) ([]Diagnostic, error)
+
+	// Run runs an arbitrary brew subcommand and returns its output
+	Run(ctx context.Context, args []string) (string, error)
+
+	// Stream runs a brew subcommand and sends its output line by line. The
+	// error channel receives the result after the output channel is closed.
+	Stream(ctx context.Context, args []string) (<-chan string, <-chan error)
+
 	// ListTaps returns all taps
 	ListTaps(ctx context.Context) ([]Tap, error)
 
@@ -51,8 +84,26 @@ type Client interface {
 	// Cleanup removes old versions and cache
 	Cleanup(ctx context.Context) error
 
+	// CleanupFormula removes the old versions and cache of one formula
+	CleanupFormula(ctx context.Context, name string) error
+
 	// Autoremove uninstalls formulae that were only installed as dependencies
 	Autoremove(ctx context.Context) error
+
+	// CleanupDryRun returns what Cleanup would remove
+	CleanupDryRun(ctx context.Context) ([]CleanupItem, error)
+
+	// CleanupPackages removes the old versions and cache of the named packages
+	CleanupPackages(ctx context.Context, names []string) error
+
+	// AutoremoveDryRun returns the formulae Autoremove would uninstall
+	AutoremoveDryRun(ctx context.Context) ([]CleanupItem, error)
+
+	// DiskUsage returns the on-disk size of every keg and cask, including old versions
+	DiskUsage(ctx context.Context) ([]DiskUsage, error)
+
+	// LastUpdate returns when brew update last fetched new package data
+	LastUpdate(ctx context.Context) (time.Time, error)
 }
 
 // NewClient creates a new Homebrew client
@@ -63,27 +114,20 @@ func NewClient() Client {
 type client struct{}
 
 func (c *client) ListInstalled(ctx context.Context, formulae bool, casks bool) ([]Package, error) {
-	var packages []Package
-
-	if formulae {
-		output, err := execute(ctx, "list", "--formula", "--versions")
-		if err != nil {
-			return nil, err
-		}
-		formulas := parsePackageNamesWithVersions(output, TypeFormula)
-		packages = append(packages, formulas...)
+	args := []string{"info", "--json=v2", "--installed"}
+	if formulae && !casks {
+		args = append(args, "--formula")
+	} else if casks && !formulae {
+		args = append(args, "--cask")
+	} else if !formulae && !casks {
+		return []Package{}, nil
 	}
 
-	if casks {
-		output, err := execute(ctx, "list", "--cask", "--versions")
-		if err != nil {
-			return nil, err
-		}
-		caskList := parsePackageNamesWithVersions(output, TypeCask)
-		packages = append(packages, caskList...)
+	output, err := execute(ctx, args...)
+	if err != nil {
+		return nil, err
 	}
-
-	return packages, nil
+	return parseInstalledJSON(output)
 }
 
 func (c *client) Search(ctx context.Context, query string) ([]Package, error) {
@@ -95,48 +139,138 @@ func (c *client) Search(ctx context.Context, query string) ([]Package, error) {
 }
 
 func (c *client) Info(ctx context.Context, name string, cask bool) (*PackageInfo, error) {
-	args := []string{"info", name}
+	pkgType := TypeFormula
 	if cask {
-		args = append(args, "--cask")
+		pkgType = TypeCask
 	}
 
-	output, err := execute(ctx, args...)
-	if err != nil {
-		return nil, err
+	// Without --formula, brew falls back to a cask of the same name
+	args := []string{"info", name, "--formula"}
+	if cask {
+		args[2] = "--cask"
 	}
 
-	pkgType := TypeFormula
-	if cask {
-		pkgType = TypeCask
+	output, err := execute(ctx, append(args, "--json=v2")...)
+	if err == nil {
+		if info, err := parsePackageInfo(output, pkgType); err == nil {
+			return info, nil
+		}
 	}
 
+	// Fall back to the text output, e.g. for brew versions without v2 JSON
+	output, err = execute(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
 	return parsePackageInfoText(output, name, pkgType), nil
 }
 
 func (c *client) Install(ctx context.Context, name string, opts InstallOptions) error {
-	args := []string{"install", name}
-	if opts.Cask {
-		args = append(args, "--cask")
+	_, err := execute(ctx, InstallArgs(name, opts)...)
+	return err
+}
+
+func (c *client) Uninstall(ctx context.Context, name string, opts UninstallOptions) error {
+	_, err := execute(ctx, UninstallArgs(name, opts)...)
+	return err
+}
+
+func (c *client) Reinstall(ctx context.Context, name string, opts InstallOptions) error {
+	_, err := execute(ctx, ReinstallArgs(name, opts)...)
+	return err
+}
+
+func (c *client) Link(ctx context.Context, name string, opts LinkOptions) (string, error) {
+	return execute(ctx, LinkArgs(name, opts)...)
+}
+
+func (c *client) Unlink(ctx context.Context, name string) error {
+	_, err := execute(ctx, "unlink", name)
+	return err
+}
+
+func (c *client) SwitchVersion(ctx context.Context, name, version string) error {
+	if filepath.Base(name) != name || filepath.Base(version) != version {
+		return fmt.Errorf("invalid formula %s %s", name, version)
 	}
-	if opts.Force {
-		args = append(args, "--force")
+	cellar, err := execute(ctx, "--cellar")
+	if err != nil {
+		return err
+	}
+	prefix, err := execute(ctx, "--prefix")
+	if err != nil {
+		return err
+	}
+	keg := filepath.Join(strings.TrimSpace(cellar), name, version)
+	if info, err := os.Stat(keg); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s %s is not in the Cellar", name, version)
 	}
 
-	_, err := execute(ctx, args...)
+	// brew links the keg the opt link points at, so point it at the new
+	// version between unlinking and linking
+	opt := filepath.Join(strings.TrimSpace(prefix), "opt", name)
+	if info, err := os.Lstat(opt); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a symlink", opt)
+	}
+	previous, _ := os.Readlink(opt)
+	// brew records linked kegs here, keg-only formulae aren't linked
+	_, err = os.Lstat(filepath.Join(strings.TrimSpace(prefix), "var", "homebrew", "linked", name))
+	linked := err == nil
+
+	if err := c.Unlink(ctx, name); err != nil {
+		return err
+	}
+	target, err := filepath.Rel(filepath.Dir(opt), keg)
+	if err != nil {
+		target = keg
+	}
+	err = replaceSymlink(target, opt)
+	if err == nil {
+		_, err = c.Link(ctx, name, LinkOptions{})
+	}
+	if err != nil {
+		if restoreErr := c.restoreLink(ctx, name, opt, previous, linked); restoreErr != nil {
+			return fmt.Errorf("%w, and restoring the previous version failed: %v", err, restoreErr)
+		}
+	}
 	return err
 }
 
-func (c *client) Uninstall(ctx context.Context, name string, opts UninstallOptions) error {
-	args := []string{"uninstall", name}
-	if opts.Cask {
-		args = append(args, "--cask")
+// restoreLink undoes a failed switch: it unlinks what brew linked before
+// failing, points the opt link back at the previous keg and links that keg
+// again if it was linked
+func (c *client) restoreLink(ctx context.Context, name, opt, previous string, linked bool) error {
+	if err := c.Unlink(ctx, name); err != nil {
+		return err
 	}
-	if opts.Force {
-		args = append(args, "--force")
+	if previous == "" {
+		if err := os.Remove(opt); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := replaceSymlink(previous, opt); err != nil {
+		return err
 	}
+	if linked {
+		_, err := c.Link(ctx, name, LinkOptions{})
+		return err
+	}
+	return nil
+}
 
-	_, err := execute(ctx, args...)
-	return err
+// replaceSymlink points link at target, replacing what link was
+func replaceSymlink(target, link string) error {
+	tmp := link + ".brewst"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("failed to link %s: %w", link, err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to link %s: %w", link, err)
+	}
+	return nil
 }
 
 func (c *client) Update(ctx context.Context) error {
@@ -144,20 +278,41 @@ func (c *client) Update(ctx context.Context) error {
 	return err
 }
 
-func (c *client) Upgrade(ctx context.Context, packages []string) error {
-	args := []string{"upgrade"}
-	args = append(args, packages...)
-
-	_, err := execute(ctx, args...)
+func (c *client) Upgrade(ctx context.Context, packages []string, opts UpgradeOptions) error {
+	_, err := execute(ctx, UpgradeArgs(packages, opts)...)
 	return err
 }
 
-func (c *client) Outdated(ctx context.Context) ([]OutdatedPackage, error) {
-	output, err := execute(ctx, "outdated")
+func (c *client) Outdated(ctx context.Context, greedy bool) ([]OutdatedPackage, error) {
+	output, err := execute(ctx, "outdated", "--json=v2")
 	if err != nil {
-		return []OutdatedPackage{}, nil
+		return nil, err
+	}
+	packages, err := parseOutdated(output)
+	if err != nil || !greedy {
+		return packages, err
+	}
+
+	// Casks that only show up with --greedy update themselves
+	output, err = execute(ctx, "outdated", "--json=v2", "--greedy")
+	if err != nil {
+		return nil, err
+	}
+	greedyPackages, err := parseOutdated(output)
+	if err != nil {
+		return nil, err
 	}
-	return parseOutdatedText(output), nil
+
+	regular := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		regular[string(pkg.Type)+"/"+pkg.Name] = true
+	}
+	for i := range greedyPackages {
+		pkg := &greedyPackages[i]
+		pkg.AutoUpdates = pkg.Type == TypeCask && !regular[string(pkg.Type)+"/"+pkg.Name]
+	}
+
+	return greedyPackages, nil
 }
 
 func (c *client) Pin(ctx context.Context, name string) error {
@@ -171,7 +326,136 @@ func (c *client) Unpin(ctx context.Context, name string) error {
 }
 
 func (c *client) Doctor(ctx context.Context) (string, error) {
-	return execute(ctx, "doctor")
+	output, err := executeCombined(ctx, "doctor")
+	// brew doctor exits with status 1 when it found warnings
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && strings.Contains(output, "Warning:") {
+		return output, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("brew doctor failed: %w", err)
+	}
+	return output, nil
+}
+
+func (c *client) Diagnose(ctx context.Context) ([]Diagnostic, error) {
+	output, err := c.Doctor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseDoctor(output), nil
+}
+
+func (c *client) Run(ctx context.Context, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("no brew command given")
+	}
+	output, err := executeCombined(ctx, args...)
+	if err != nil {
+		return output, fmt.Errorf("brew %s failed: %w", args[0], err)
+	}
+	return output, nil
+}
+
+func (c *client) Stream(ctx context.Context, args []string) (<-chan string, <-chan error) {
+	if len(args) == 0 {
+		output := make(chan string)
+		errs := make(chan error, 1)
+		close(output)
+		errs <- fmt.Errorf("no brew command given")
+		close(errs)
+		return output, errs
+	}
+	return executeStream(ctx, args...)
+}
+
+// InstallArgs returns the brew arguments for installing a package
+func InstallArgs(name string, opts InstallOptions) []string {
+	args := []string{"install", name}
+	if opts.Cask {
+		args = append(args, "--cask")
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	if opts.HEAD {
+		args = append(args, "--HEAD")
+	}
+	if opts.BuildFromSource {
+		args = append(args, "--build-from-source")
+	}
+	if opts.ForceBottle {
+		args = append(args, "--force-bottle")
+	}
+	if opts.IgnoreDependencies {
+		args = append(args, "--ignore-dependencies")
+	}
+	if opts.SkipPostInstall {
+		args = append(args, "--skip-post-install")
+	}
+	return append(args, opts.Flags...)
+}
+
+// ReinstallArgs returns the brew arguments for reinstalling a package. brew
+// reinstall keeps the spec and formula options of the installed keg, only
+// how it is poured or built can change.
+func ReinstallArgs(name string, opts InstallOptions) []string {
+	args := []string{"reinstall", name}
+	if opts.Cask {
+		args = append(args, "--cask")
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	if opts.BuildFromSource {
+		args = append(args, "--build-from-source")
+	}
+	if opts.ForceBottle {
+		args = append(args, "--force-bottle")
+	}
+	return args
+}
+
+// LinkArgs returns the brew arguments for linking a formula
+func LinkArgs(name string, opts LinkOptions) []string {
+	args := []string{"link", name}
+	if opts.Overwrite {
+		args = append(args, "--overwrite")
+	}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	return args
+}
+
+// UninstallArgs returns the brew arguments for uninstalling a package
+func UninstallArgs(name string, opts UninstallOptions) []string {
+	args := []string{"uninstall", name}
+	if opts.Cask {
+		args = append(args, "--cask")
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	return args
+}
+
+// UpgradeArgs returns the brew arguments for upgrading packages
+func UpgradeArgs(packages []string, opts UpgradeOptions) []string {
+	args := []string{"upgrade"}
+	if opts.Greedy {
+		args = append(args, "--greedy")
+	}
+	if opts.BuildFromSource {
+		args = append(args, "--build-from-source")
+	}
+	if opts.ForceBottle {
+		args = append(args, "--force-bottle")
+	}
+	if opts.FetchHEAD {
+		args = append(args, "--fetch-HEAD")
+	}
+	return append(args, packages...)
 }
 
 func (c *client) ListTaps(ctx context.Context) ([]Tap, error) {
@@ -197,7 +481,117 @@ func (c *client) Cleanup(ctx context.Context) error {
 	return err
 }
 
+func (c *client) CleanupFormula(ctx context.Context, name string) error {
+	_, err := execute(ctx, "cleanup", name)
+	return err
+}
+
 func (c *client) Autoremove(ctx context.Context) error {
 	_, err := execute(ctx, "autoremove")
 	return err
 }
+
+func (c *client) CleanupDryRun(ctx context.Context) ([]CleanupItem, error) {
+	output, err := execute(ctx, "cleanup", "--dry-run")
+	if err != nil {
+		return nil, err
+	}
+	return parseCleanupDryRun(output), nil
+}
+
+func (c *client) CleanupPackages(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	_, err := execute(ctx, append([]string{"cleanup"}, names...)...)
+	return err
+}
+
+func (c *client) AutoremoveDryRun(ctx context.Context) ([]CleanupItem, error) {
+	output, err := execute(ctx, "autoremove", "--dry-run")
+	if err != nil {
+		return nil, err
+	}
+	return parseAutoremoveDryRun(output), nil
+}
+
+func (c *client) DiskUsage(ctx context.Context) ([]DiskUsage, error) {
+	prefix, err := execute(ctx, "--prefix")
+	if err != nil {
+		return nil, err
+	}
+	cellar, err := execute(ctx, "--cellar")
+	if err != nil {
+		return nil, err
+	}
+	caskroom, err := execute(ctx, "--caskroom")
+	if err != nil {
+		return nil, err
+	}
+
+	// Most casks move their apps out of the Caskroom
+	output, err := execute(ctx, "info", "--json=v2", "--installed", "--cask")
+	if err != nil {
+		return nil, err
+	}
+	apps, err := parseCaskApps(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return diskUsage(ctx,
+		strings.TrimSpace(prefix),
+		strings.TrimSpace(cellar),
+		strings.TrimSpace(caskroom),
+		apps, caskAppDirs())
+}
+
+// caskAppDirs returns where casks install apps: --appdir from
+// HOMEBREW_CASK_OPTS, /Applications and ~/Applications
+func caskAppDirs() []string {
+	home, _ := os.UserHomeDir()
+	var dirs []string
+	for _, opt := range strings.Fields(os.Getenv("HOMEBREW_CASK_OPTS")) {
+		if dir, ok := strings.CutPrefix(opt, "--appdir="); ok {
+			dir = strings.Trim(dir, `"'`)
+			if rest, ok := strings.CutPrefix(dir, "~/"); ok && home != "" {
+				dir = filepath.Join(home, rest)
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs = append(dirs, "/Applications")
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	return dirs
+}
+
+func (c *client) LastUpdate(ctx context.Context) (time.Time, error) {
+	repository, err := execute(ctx, "--repository")
+	if err != nil {
+		return time.Time{}, err
+	}
+	cache, err := execute(ctx, "--cache")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// brew update fetches the Homebrew repository and downloads the API data
+	candidates := []string{
+		filepath.Join(strings.TrimSpace(repository), ".git", "FETCH_HEAD"),
+		filepath.Join(strings.TrimSpace(cache), "api", "formula.jws.json"),
+		filepath.Join(strings.TrimSpace(cache), "api", "cask.jws.json"),
+	}
+
+	var latest time.Time
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	if latest.IsZero() {
+		return latest, fmt.Errorf("brew update has never run")
+	}
+	return latest, nil
+}
@@ -0,0 +1,201 @@
+package brew
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// diskJob is a single version waiting to be measured
+type diskJob struct {
+	usage   int // index into the usage slice
+	version int // index into the usage's versions
+	paths   []string
+}
+
+// diskUsage measures every keg in the Cellar and every Caskroom entry. Apps
+// casks moved out of the Caskroom, by cask token, are found in appDirs and
+// counted towards the cask's current version.
+func diskUsage(ctx context.Context, prefix, cellar, caskroom string, apps map[string][]string, appDirs []string) ([]DiskUsage, error) {
+	var usage []DiskUsage
+
+	formulae, err := scanRacks(cellar, TypeFormula, func(name string) string {
+		// The opt symlink points at the keg that is currently in use
+		target, err := os.Readlink(filepath.Join(prefix, "opt", name))
+		if err != nil {
+			return ""
+		}
+		return filepath.Base(target)
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage = append(usage, formulae...)
+
+	casks, err := scanRacks(caskroom, TypeCask, nil)
+	if err != nil {
+		return nil, err
+	}
+	usage = append(usage, casks...)
+
+	// Measure version directories concurrently
+	var jobs []diskJob
+	root := map[PackageType]string{TypeFormula: cellar, TypeCask: caskroom}
+	for i, u := range usage {
+		for j, v := range u.Versions {
+			job := diskJob{
+				usage:   i,
+				version: j,
+				paths:   []string{filepath.Join(root[u.Type], u.Name, v.Version)},
+			}
+			if u.Type == TypeCask && v.Current {
+				job.paths = append(job.paths, appPaths(apps[u.Name], appDirs)...)
+			}
+			jobs = append(jobs, job)
+		}
+	}
+
+	jobChan := make(chan diskJob)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobChan {
+				// Each job owns a distinct element, no locking needed
+				var size int64
+				for _, path := range job.paths {
+					size += dirSize(ctx, path)
+				}
+				usage[job.usage].Versions[job.version].Size = size
+			}
+		}()
+	}
+
+send:
+	for _, job := range jobs {
+		select {
+		case jobChan <- job:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobChan)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range usage {
+		for _, v := range usage[i].Versions {
+			usage[i].Size += v.Size
+			if !v.Current {
+				usage[i].Reclaimable += v.Size
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// scanRacks lists <root>/<name>/<version> directories. current returns the
+// version in use for a name, or "" to fall back to the most recent one.
+func scanRacks(root string, pkgType PackageType, current func(name string) string) ([]DiskUsage, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return []DiskUsage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]DiskUsage, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		versionEntries, err := os.ReadDir(filepath.Join(root, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		u := DiskUsage{Name: entry.Name(), Type: pkgType}
+		var newest int
+		var newestTime int64
+		for _, v := range versionEntries {
+			if !v.IsDir() || strings.HasPrefix(v.Name(), ".") {
+				continue
+			}
+			if info, err := v.Info(); err == nil && info.ModTime().UnixNano() > newestTime {
+				newestTime = info.ModTime().UnixNano()
+				newest = len(u.Versions)
+			}
+			u.Versions = append(u.Versions, KegUsage{Version: v.Name()})
+		}
+		if len(u.Versions) == 0 {
+			continue
+		}
+
+		inUse := ""
+		if current != nil {
+			inUse = current(u.Name)
+		}
+		found := false
+		for i := range u.Versions {
+			if u.Versions[i].Version == inUse {
+				u.Versions[i].Current = true
+				found = true
+			}
+		}
+		if !found {
+			u.Versions[newest].Current = true
+		}
+
+		usage = append(usage, u)
+	}
+
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Name < usage[j].Name })
+	return usage, nil
+}
+
+// appPaths finds app bundles in the first app directory that has them
+func appPaths(apps, appDirs []string) []string {
+	var paths []string
+	for _, app := range apps {
+		for _, dir := range appDirs {
+			path := filepath.Join(dir, app)
+			if _, err := os.Lstat(path); err == nil {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// dirSize returns the total size of regular files below path
+func dirSize(ctx context.Context, path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,280 @@
+package brew
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DiagnosticKind identifies the kind of problem brew doctor reported
+type DiagnosticKind string
+
+const (
+	DiagnosticUnlinkedKegs   DiagnosticKind = "unlinked_kegs"
+	DiagnosticToolchain      DiagnosticKind = "toolchain"
+	DiagnosticBrokenSymlinks DiagnosticKind = "broken_symlinks"
+	DiagnosticConfigScripts  DiagnosticKind = "config_scripts"
+	DiagnosticUnbrewedFiles  DiagnosticKind = "unbrewed_files"
+	DiagnosticPermissions    DiagnosticKind = "permissions"
+	DiagnosticDeprecated     DiagnosticKind = "deprecated"
+	DiagnosticOther          DiagnosticKind = "other"
+)
+
+// DiagnosticSeverity ranks how serious a diagnostic is
+type DiagnosticSeverity int
+
+const (
+	SeverityInfo DiagnosticSeverity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the severity name
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// Diagnostic is a single warning or error from brew doctor
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Severity DiagnosticSeverity
+	Title    string
+	Message  string   // Explanation Homebrew printed below the title
+	Items    []string // Affected kegs, files or directories
+	Paths    []string // Items that are filesystem paths
+	Commands []string // Suggested commands
+}
+
+// commandPrefixes are the first words of lines we treat as suggested commands
+var commandPrefixes = []string{
+	"brew ", "sudo ", "rm ", "xcode-select ", "softwareupdate ", "git ",
+	"chmod ", "chown ", "mkdir ", "ln ", "launchctl ", "echo ", "export ", "cd ",
+}
+
+var backtickPattern = regexp.MustCompile("`([^`]+)`")
+
+// parseDoctor parses output from brew doctor into diagnostics
+func parseDoctor(output string) []Diagnostic {
+	var diagnostics []Diagnostic
+	var current *Diagnostic
+	var body []string
+
+	flush := func() {
+		if current != nil {
+			finishDiagnostic(current, body)
+			diagnostics = append(diagnostics, *current)
+		}
+		current = nil
+		body = nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		severity := SeverityWarning
+		title, isWarning := strings.CutPrefix(trimmed, "Warning:")
+		if !isWarning {
+			title, isWarning = strings.CutPrefix(trimmed, "Error:")
+			severity = SeverityError
+		}
+
+		if isWarning {
+			flush()
+			current = &Diagnostic{Severity: severity, Title: strings.TrimSpace(title)}
+			continue
+		}
+
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	return diagnostics
+}
+
+// finishDiagnostic fills in the message, items, commands and kind
+func finishDiagnostic(d *Diagnostic, body []string) {
+	var message []string
+	var hinted []string // Commands from `backticks` in the text
+
+	for _, title := range backtickPattern.FindAllStringSubmatch(d.Title, -1) {
+		hinted = append(hinted, title[1])
+	}
+
+	for _, line := range body {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		switch {
+		case indented && isCommand(trimmed):
+			d.Commands = append(d.Commands, trimmed)
+		case indented:
+			d.Items = append(d.Items, trimmed)
+			if strings.HasPrefix(trimmed, "/") || strings.HasPrefix(trimmed, "~") {
+				d.Paths = append(d.Paths, trimmed)
+			}
+		default:
+			message = append(message, trimmed)
+			for _, match := range backtickPattern.FindAllStringSubmatch(trimmed, -1) {
+				hinted = append(hinted, match[1])
+			}
+		}
+	}
+	d.Message = strings.Join(message, " ")
+
+	// "Run `brew link` on these:" applies the command to every item
+	text := strings.ToLower(d.Title + " " + d.Message)
+	perItem := len(d.Items) > 0 && len(d.Paths) == 0 &&
+		(strings.Contains(text, "these") || strings.Contains(text, "following"))
+	for _, hint := range hinted {
+		if !isCommand(hint + " ") {
+			continue
+		}
+		if perItem {
+			for _, item := range d.Items {
+				d.Commands = append(d.Commands, hint+" "+item)
+			}
+		} else {
+			d.Commands = append(d.Commands, hint)
+		}
+	}
+	d.Commands = dedupe(d.Commands)
+
+	d.Kind = diagnosticKind(strings.ToLower(d.Title + " " + d.Message))
+	switch d.Kind {
+	case DiagnosticPermissions:
+		d.Severity = SeverityError
+	case DiagnosticConfigScripts, DiagnosticUnbrewedFiles, DiagnosticDeprecated, DiagnosticOther:
+		if d.Severity == SeverityWarning {
+			d.Severity = SeverityInfo
+		}
+	}
+}
+
+// diagnosticKind classifies a diagnostic from its lowercased text
+func diagnosticKind(text string) DiagnosticKind {
+	switch {
+	case strings.Contains(text, "unlinked kegs"):
+		return DiagnosticUnlinkedKegs
+	case strings.Contains(text, "command line tools"), strings.Contains(text, "xcode"):
+		return DiagnosticToolchain
+	case strings.Contains(text, "broken symlink"):
+		return DiagnosticBrokenSymlinks
+	case strings.Contains(text, "config\" scripts"), strings.Contains(text, "config scripts"):
+		return DiagnosticConfigScripts
+	case strings.Contains(text, "unbrewed"):
+		return DiagnosticUnbrewedFiles
+	case strings.Contains(text, "not writable"), strings.Contains(text, "permission"):
+		return DiagnosticPermissions
+	case strings.Contains(text, "deprecated"), strings.Contains(text, "disabled"):
+		return DiagnosticDeprecated
+	default:
+		return DiagnosticOther
+	}
+}
+
+func isCommand(line string) bool {
+	for _, prefix := range commandPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FixArgs returns the brew arguments of a suggested fix, split like a shell
+// would. Only plain brew commands can be run as they are: ok is false for
+// other commands and for anything using pipes, redirections, variables or
+// other shell syntax.
+func FixArgs(command string) (args []string, ok bool) {
+	words, ok := shellWords(command)
+	if !ok || len(words) < 2 || words[0] != "brew" {
+		return nil, false
+	}
+	return words[1:], true
+}
+
+// shellWords splits a command line into words, honouring single quotes,
+// double quotes and backslashes. ok is false for unquoted shell operators
+// and for expansions the shell would have to do.
+func shellWords(line string) (words []string, ok bool) {
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes a few characters
+			if quote == '"' && !strings.ContainsRune("$`\"\\", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			case '$', '`':
+				return nil, false
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case strings.ContainsRune("|&;<>()$`*?[]{}~#!\n", r):
+			return nil, false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, false
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, true
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
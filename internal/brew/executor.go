@@ -1,9 +1,11 @@
 package brew
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 )
@@ -15,7 +17,9 @@ func execute(ctx context.Context, args ...string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	trace := startTrace(cmd)
 	err := cmd.Run()
+	trace.finish(cmd, stdout.String(), stderr.String(), err)
 	if err != nil {
 		errMsg := strings.TrimSpace(stderr.String())
 		if errMsg == "" {
@@ -27,40 +31,70 @@ func execute(ctx context.Context, args ...string) (string, error) {
 	return stdout.String(), nil
 }
 
-// executeStream runs a brew command and returns output line by line via a channel
+// executeCombined runs a brew command and returns stdout and stderr interleaved,
+// along with the command error. Output is returned even when the command fails.
+func executeCombined(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "brew", args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	trace := startTrace(cmd)
+	err := cmd.Run()
+	trace.finish(cmd, output.String(), "", err)
+	return output.String(), err
+}
+
+// executeStream runs a brew command and sends its output line by line, stdout
+// and stderr interleaved. The error channel receives the command's result once
+// the output channel is closed.
 func executeStream(ctx context.Context, args ...string) (<-chan string, <-chan error) {
 	outputChan := make(chan string)
 	errorChan := make(chan error, 1)
 
 	go func() {
-		defer close(outputChan)
 		defer close(errorChan)
 
+		reader, writer := io.Pipe()
 		cmd := exec.CommandContext(ctx, "brew", args...)
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			errorChan <- fmt.Errorf("failed to create stdout pipe: %w", err)
-			return
-		}
+		cmd.Stdout = writer
+		cmd.Stderr = writer
+
+		trace := startTrace(cmd)
+		var traced limitedBuffer
 
 		if err := cmd.Start(); err != nil {
+			trace.finish(cmd, "", "", err)
+			close(outputChan)
 			errorChan <- fmt.Errorf("failed to start command: %w", err)
 			return
 		}
 
-		buf := make([]byte, 4096)
-		for {
-			n, err := stdout.Read(buf)
-			if n > 0 {
-				outputChan <- string(buf[:n])
+		waitErr := make(chan error, 1)
+		go func() {
+			waitErr <- cmd.Wait()
+			writer.Close()
+		}()
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if trace != nil {
+				traced.add(scanner.Text())
 			}
-			if err != nil {
-				break
+			select {
+			case outputChan <- scanner.Text():
+			case <-ctx.Done():
+				// Keep draining so the command can exit
 			}
 		}
+		_, _ = io.Copy(io.Discard, reader)
+		close(outputChan)
 
-		if err := cmd.Wait(); err != nil {
-			errorChan <- fmt.Errorf("command failed: %w", err)
+		err := <-waitErr
+		trace.finish(cmd, traced.String(), "", err)
+		if err != nil {
+			errorChan <- fmt.Errorf("brew %s failed: %w", args[0], err)
 		}
 	}()
 
@@ -3,7 +3,11 @@ package brew
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 )
 
 // parsePackageNames parses plain text output from brew list (just package names)
@@ -34,7 +38,8 @@ func parsePackageNames(output string, pkgType PackageType) []Package {
 }
 
 // parsePackageNamesWithVersions parses output from brew list --versions
-// Format: "package-name 1.2.3" or "package-name 1.2.3 1.2.4" (multiple versions)
+// Format: "package-name 1.2.3" or "package-name 1.2.3 1.2.4" (multiple versions,
+// oldest first). The newest version is the package's version.
 func parsePackageNamesWithVersions(output string, pkgType PackageType) []Package {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	packages := make([]Package, 0)
@@ -52,17 +57,19 @@ func parsePackageNamesWithVersions(output string, pkgType PackageType) []Package
 		}
 
 		name := fields[0]
+		versions := fields[1:]
 		version := ""
-		if len(fields) > 1 {
-			version = fields[1]
+		if len(versions) > 0 {
+			version = versions[len(versions)-1]
 		}
 
 		pkg := Package{
-			Name:      name,
-			FullName:  name,
-			Version:   version,
-			Type:      pkgType,
-			Installed: true,
+			Name:              name,
+			FullName:          name,
+			Version:           version,
+			Type:              pkgType,
+			Installed:         true,
+			InstalledVersions: versions,
 		}
 		packages = append(packages, pkg)
 	}
@@ -124,106 +131,102 @@ func parsePackages(output string, pkgType PackageType) ([]Package, error) {
 	return packages, nil
 }
 
-// parsePackageInfo parses JSON output from brew info command
+// parsePackageInfo parses output from brew info --json=v2 for a single package
 func parsePackageInfo(output string, pkgType PackageType) (*PackageInfo, error) {
-	var rawPackages []map[string]interface{}
-	if err := json.Unmarshal([]byte(output), &rawPackages); err != nil {
+	var raw infoJSON
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse package info: %w", err)
 	}
 
-	if len(rawPackages) == 0 {
-		return nil, fmt.Errorf("no package information found")
-	}
-
-	raw := rawPackages[0]
-	info := &PackageInfo{
-		Package: Package{
-			Type: pkgType,
-		},
-	}
-
-	if name, ok := raw["name"].(string); ok {
-		info.Name = name
-	}
-	if fullName, ok := raw["full_name"].(string); ok {
-		info.FullName = fullName
-	} else {
-		info.FullName = info.Name
-	}
-	if version, ok := raw["version"].(string); ok {
-		info.Version = version
-	}
-	if desc, ok := raw["desc"].(string); ok {
-		info.Description = desc
-	}
-	if homepage, ok := raw["homepage"].(string); ok {
-		info.Homepage = homepage
-	}
-	if caveats, ok := raw["caveats"].(string); ok {
-		info.Caveats = caveats
-	}
-
-	// Parse dependencies
-	if deps, ok := raw["dependencies"].([]interface{}); ok {
-		info.Dependencies = make([]string, 0, len(deps))
-		for _, dep := range deps {
-			if depStr, ok := dep.(string); ok {
-				info.Dependencies = append(info.Dependencies, depStr)
+	switch {
+	case pkgType == TypeCask && len(raw.Casks) > 0:
+		c := raw.Casks[0]
+		info := &PackageInfo{
+			Package:   caskPackage(c),
+			Caveats:   c.Caveats,
+			Conflicts: c.ConflictsWith.Cask,
+		}
+		info.OnRequest = c.Installed != ""
+		if c.InstalledTime > 0 {
+			info.InstallDate = time.Unix(c.InstalledTime, 0)
+		}
+		return info, nil
+	case pkgType == TypeFormula && len(raw.Formulae) > 0:
+		f := raw.Formulae[0]
+		info := &PackageInfo{
+			Package:      formulaPackage(f),
+			Dependencies: f.Dependencies,
+			BuildDeps:    f.BuildDependencies,
+			Caveats:      f.Caveats,
+			Conflicts:    f.ConflictsWith,
+			Options:      f.Options,
+			HasHead:      f.Versions.Head != "",
+		}
+		// Install details come from the keg of the current version
+		for _, keg := range f.Installed {
+			if keg.Version != info.Version {
+				continue
 			}
-		}
-	}
-
-	// Parse build dependencies
-	if buildDeps, ok := raw["build_dependencies"].([]interface{}); ok {
-		info.BuildDeps = make([]string, 0, len(buildDeps))
-		for _, dep := range buildDeps {
-			if depStr, ok := dep.(string); ok {
-				info.BuildDeps = append(info.BuildDeps, depStr)
+			info.OnRequest = keg.InstalledOnRequest
+			info.FromBottle = keg.PouredFromBottle
+			if keg.Time > 0 {
+				info.InstallDate = time.Unix(keg.Time, 0)
 			}
 		}
+		return info, nil
 	}
 
-	return info, nil
+	return nil, fmt.Errorf("no package information found")
 }
 
-// parseOutdated parses JSON output from brew outdated command
+// outdatedJSON mirrors the output of brew outdated --json=v2
+type outdatedJSON struct {
+	Formulae []outdatedEntryJSON `json:"formulae"`
+	Casks    []outdatedEntryJSON `json:"casks"`
+}
+
+type outdatedEntryJSON struct {
+	Name              string   `json:"name"`
+	InstalledVersions []string `json:"installed_versions"`
+	CurrentVersion    string   `json:"current_version"`
+	Pinned            bool     `json:"pinned"`
+	PinnedVersion     string   `json:"pinned_version"`
+}
+
+// parseOutdated parses output from brew outdated --json=v2
 func parseOutdated(output string) ([]OutdatedPackage, error) {
 	if strings.TrimSpace(output) == "" {
 		return []OutdatedPackage{}, nil
 	}
 
-	var rawPackages []map[string]interface{}
-	if err := json.Unmarshal([]byte(output), &rawPackages); err != nil {
+	var raw outdatedJSON
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse outdated packages: %w", err)
 	}
 
-	packages := make([]OutdatedPackage, 0, len(rawPackages))
-	for _, raw := range rawPackages {
-		pkg := OutdatedPackage{}
-
-		if name, ok := raw["name"].(string); ok {
-			pkg.Name = name
-		}
-
-		// Handle installed versions - might be array or string
-		if versions, ok := raw["installed_versions"].([]interface{}); ok && len(versions) > 0 {
-			if version, ok := versions[0].(string); ok {
-				pkg.CurrentVersion = version
+	packages := make([]OutdatedPackage, 0, len(raw.Formulae)+len(raw.Casks))
+	add := func(entries []outdatedEntryJSON, pkgType PackageType) {
+		for _, entry := range entries {
+			pkg := OutdatedPackage{
+				Name:              entry.Name,
+				Type:              pkgType,
+				InstalledVersions: entry.InstalledVersions,
+				LatestVersion:     entry.CurrentVersion,
+				Pinned:            entry.Pinned,
+				PinnedVersion:     entry.PinnedVersion,
 			}
-		} else if version, ok := raw["installed_versions"].(string); ok {
-			pkg.CurrentVersion = version
-		}
-
-		if version, ok := raw["current_version"].(string); ok {
-			pkg.LatestVersion = version
-		}
-
-		if pinned, ok := raw["pinned"].(bool); ok {
-			pkg.Pinned = pinned
+			// A pinned formula stays on the pinned keg, otherwise the newest
+			// keg is the one being upgraded
+			pkg.CurrentVersion = newestVersion(entry.InstalledVersions)
+			if entry.Pinned && slices.Contains(entry.InstalledVersions, entry.PinnedVersion) {
+				pkg.CurrentVersion = entry.PinnedVersion
+			}
+			pkg.Update = ClassifyUpdate(pkg.CurrentVersion, pkg.LatestVersion)
+			packages = append(packages, pkg)
 		}
-
-		packages = append(packages, pkg)
 	}
+	add(raw.Formulae, TypeFormula)
+	add(raw.Casks, TypeCask)
 
 	return packages, nil
 }
@@ -419,6 +422,7 @@ func parseOutdatedText(output string) []OutdatedPackage {
 			// Remove parentheses from current version
 			pkg.CurrentVersion = strings.Trim(parts[1], "()")
 			pkg.LatestVersion = parts[3]
+			pkg.Update = ClassifyUpdate(pkg.CurrentVersion, pkg.LatestVersion)
 		}
 
 		packages = append(packages, pkg)
@@ -426,3 +430,336 @@ func parseOutdatedText(output string) []OutdatedPackage {
 
 	return packages
 }
+
+// infoJSON mirrors the output of brew info --json=v2
+type infoJSON struct {
+	Formulae []formulaJSON `json:"formulae"`
+	Casks    []caskJSON    `json:"casks"`
+}
+
+type formulaJSON struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Tap      string `json:"tap"`
+	Desc     string `json:"desc"`
+	Homepage string `json:"homepage"`
+	License  string `json:"license"`
+	Versions struct {
+		Stable string `json:"stable"`
+		Head   string `json:"head"`
+	} `json:"versions"`
+	URLs struct {
+		Stable struct {
+			URL      string `json:"url"`
+			Checksum string `json:"checksum"`
+		} `json:"stable"`
+	} `json:"urls"`
+	Dependencies      []string `json:"dependencies"`
+	BuildDependencies []string `json:"build_dependencies"`
+	Caveats           string   `json:"caveats"`
+	Installed         []struct {
+		Version             string `json:"version"`
+		RuntimeDependencies []struct {
+			FullName string `json:"full_name"`
+		} `json:"runtime_dependencies"`
+		Time               int64 `json:"time"`
+		PouredFromBottle   bool  `json:"poured_from_bottle"`
+		InstalledOnRequest bool  `json:"installed_on_request"`
+	} `json:"installed"`
+	ConflictsWith []string        `json:"conflicts_with"`
+	Options       []FormulaOption `json:"options"`
+	LinkedKeg     string          `json:"linked_keg"`
+	Pinned        bool            `json:"pinned"`
+	Deprecated    bool            `json:"deprecated"`
+	Disabled      bool            `json:"disabled"`
+}
+
+type caskJSON struct {
+	Token     string `json:"token"`
+	FullToken string `json:"full_token"`
+	Tap       string `json:"tap"`
+	Desc      string `json:"desc"`
+	Homepage  string `json:"homepage"`
+	Version   string `json:"version"`
+	Installed string `json:"installed"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Caveats   string `json:"caveats"`
+	// Unix time the cask was installed
+	InstalledTime int64 `json:"installed_time"`
+	ConflictsWith struct {
+		Cask []string `json:"cask"`
+	} `json:"conflicts_with"`
+	DependsOn struct {
+		Formula []string `json:"formula"`
+		Cask    []string `json:"cask"`
+	} `json:"depends_on"`
+	Deprecated bool `json:"deprecated"`
+	Disabled   bool `json:"disabled"`
+	// Artifacts such as {"app": ["Foo.app", {"target": "Bar.app"}]}
+	Artifacts []map[string][]json.RawMessage `json:"artifacts"`
+}
+
+// parseInstalledJSON parses output from brew info --json=v2 --installed
+func parseInstalledJSON(output string) ([]Package, error) {
+	if strings.TrimSpace(output) == "" {
+		return []Package{}, nil
+	}
+
+	var raw infoJSON
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse installed packages: %w", err)
+	}
+
+	packages := make([]Package, 0, len(raw.Formulae)+len(raw.Casks))
+	for _, f := range raw.Formulae {
+		packages = append(packages, formulaPackage(f))
+	}
+	for _, c := range raw.Casks {
+		packages = append(packages, caskPackage(c))
+	}
+
+	return packages, nil
+}
+
+// parseCaskApps returns the app bundles each installed cask moved out of the
+// Caskroom, by cask token
+func parseCaskApps(output string) (map[string][]string, error) {
+	apps := make(map[string][]string)
+	if strings.TrimSpace(output) == "" {
+		return apps, nil
+	}
+
+	var raw infoJSON
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse installed casks: %w", err)
+	}
+
+	for _, c := range raw.Casks {
+		for _, artifact := range c.Artifacts {
+			app, ok := artifact["app"]
+			if !ok || len(app) == 0 {
+				continue
+			}
+			// The bundle keeps its name unless a target renames it
+			var name string
+			if json.Unmarshal(app[0], &name) != nil {
+				continue
+			}
+			for _, option := range app[1:] {
+				var target struct {
+					Target string `json:"target"`
+				}
+				if json.Unmarshal(option, &target) == nil && target.Target != "" {
+					name = target.Target
+				}
+			}
+			apps[c.Token] = append(apps[c.Token], filepath.Base(name))
+		}
+	}
+
+	return apps, nil
+}
+
+// formulaPackage converts a brew info --json=v2 formula
+func formulaPackage(f formulaJSON) Package {
+	// Prefer the linked keg, fall back to the newest installed keg
+	version := f.LinkedKeg
+	if version == "" {
+		var kegs []string
+		for _, keg := range f.Installed {
+			kegs = append(kegs, keg.Version)
+		}
+		version = newestVersion(kegs)
+	}
+	if version == "" {
+		version = f.Versions.Stable
+	}
+
+	// Dependencies the installed keg was built against, or the declared ones
+	dependencies := f.Dependencies
+	var versions []string
+	for _, keg := range f.Installed {
+		versions = append(versions, keg.Version)
+		if keg.Version == version && keg.RuntimeDependencies != nil {
+			dependencies = make([]string, 0, len(keg.RuntimeDependencies))
+			for _, dep := range keg.RuntimeDependencies {
+				dependencies = append(dependencies, shortName(dep.FullName))
+			}
+		}
+	}
+
+	pkg := Package{
+		Name:         f.Name,
+		FullName:     f.FullName,
+		Version:      version,
+		Description:  f.Desc,
+		Homepage:     f.Homepage,
+		Tap:          f.Tap,
+		Type:         TypeFormula,
+		Installed:    len(f.Installed) > 0,
+		Pinned:       f.Pinned,
+		Deprecated:   f.Deprecated || f.Disabled,
+		License:      f.License,
+		Dependencies: dependencies,
+
+		InstalledVersions: versions,
+		LinkedVersion:     f.LinkedKeg,
+	}
+	// brew only knows the download of the latest version, which is the
+	// installed one when the keg is that version or a revision of it
+	if kegVersion(version) == f.Versions.Stable {
+		pkg.SourceURL = f.URLs.Stable.URL
+		pkg.Checksum = f.URLs.Stable.Checksum
+	}
+	return pkg
+}
+
+// kegRevision matches the revision suffix of a keg name
+var kegRevision = regexp.MustCompile(`_\d+$`)
+
+// kegVersion strips the revision from a keg name, "1.2_1" is version 1.2
+func kegVersion(keg string) string {
+	return kegRevision.ReplaceAllString(keg, "")
+}
+
+// caskPackage converts a brew info --json=v2 cask. Casks don't declare a license.
+func caskPackage(c caskJSON) Package {
+	version := c.Installed
+	if version == "" {
+		version = c.Version
+	}
+
+	var versions []string
+	if c.Installed != "" {
+		versions = []string{c.Installed}
+	}
+
+	pkg := Package{
+		Name:         c.Token,
+		FullName:     c.FullToken,
+		Version:      version,
+		Description:  c.Desc,
+		Homepage:     c.Homepage,
+		Tap:          c.Tap,
+		Type:         TypeCask,
+		Installed:    c.Installed != "",
+		Deprecated:   c.Deprecated || c.Disabled,
+		Dependencies: shortNames(c.DependsOn.Formula),
+
+		CaskDependencies:  shortNames(c.DependsOn.Cask),
+		InstalledVersions: versions,
+	}
+	// The URL and checksum are for the latest version of the cask
+	if version == c.Version {
+		pkg.SourceURL = c.URL
+		pkg.Checksum = caskChecksum(c.SHA256)
+	}
+	return pkg
+}
+
+// shortName strips the tap from a full name like "user/tap/formula"
+func shortName(fullName string) string {
+	if i := strings.LastIndex(fullName, "/"); i != -1 {
+		return fullName[i+1:]
+	}
+	return fullName
+}
+
+func shortNames(fullNames []string) []string {
+	names := make([]string, len(fullNames))
+	for i, name := range fullNames {
+		names[i] = shortName(name)
+	}
+	return names
+}
+
+// caskChecksum drops the ":no_check" placeholder casks use for unversioned downloads
+func caskChecksum(sha256 string) string {
+	if sha256 == "no_check" || strings.HasPrefix(sha256, ":") {
+		return ""
+	}
+	return sha256
+}
+
+// parseCleanupDryRun parses output from brew cleanup --dry-run
+// Format: "Would remove: /path/to/file (1.2MB)" or "Would remove: /path (12 files, 3.4MB)"
+func parseCleanupDryRun(output string) []CleanupItem {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	items := make([]CleanupItem, 0)
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Would remove") && !strings.HasPrefix(line, "Would prune") {
+			continue
+		}
+
+		_, path, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+
+		item := CleanupItem{}
+		// Size is in the trailing parentheses, after an optional file count
+		if open := strings.LastIndex(path, " ("); open != -1 && strings.HasSuffix(path, ")") {
+			details := strings.Split(path[open+2:len(path)-1], ",")
+			if size, err := ParseSize(details[len(details)-1]); err == nil {
+				item.Size = size
+			}
+			path = path[:open]
+		}
+
+		item.Path = strings.TrimSpace(path)
+		item.Name = cleanupItemName(item.Path)
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// cleanupItemName derives the package a cleanup path belongs to, or "" for
+// logs and caches that aren't tied to a package
+func cleanupItemName(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if (part == "Cellar" || part == "Caskroom") && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+
+	// Cached downloads are named "<name>--<version>...", files in the
+	// downloads directory have a "<hash>--" prefix on top
+	base := parts[len(parts)-1]
+	if len(parts) > 1 && parts[len(parts)-2] == "downloads" {
+		_, base, _ = strings.Cut(base, "--")
+	}
+	if name, _, ok := strings.Cut(base, "--"); ok {
+		return name
+	}
+	return ""
+}
+
+// parseAutoremoveDryRun parses output from brew autoremove --dry-run
+// Format: "==> Would autoremove 2 unneeded formulae:" followed by one name per line
+func parseAutoremoveDryRun(output string) []CleanupItem {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	items := make([]CleanupItem, 0)
+
+	inList := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "==>") {
+			inList = strings.Contains(line, "Would autoremove")
+			continue
+		}
+		if !inList || line == "" {
+			continue
+		}
+
+		for _, name := range strings.Fields(line) {
+			items = append(items, CleanupItem{Name: name})
+		}
+	}
+
+	return items
+}
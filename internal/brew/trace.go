@@ -0,0 +1,277 @@
+package brew
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxTraceOutput   = 4096             // Bytes of stdout and stderr kept per invocation
+	maxTraceRecent   = 200              // Invocations kept for the Debug view
+	maxTraceFileSize = 10 * 1024 * 1024 // Trace file size before it is rotated
+)
+
+// Invocation is a recorded brew command
+type Invocation struct {
+	ID       int       `json:"id"`
+	Args     []string  `json:"args"`
+	Env      []string  `json:"env,omitempty"` // HOMEBREW_* variables, which change how brew behaves, with secrets masked
+	Dir      string    `json:"dir"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	ExitCode int       `json:"exit_code"` // -1 when the command didn't start or was killed
+	Stdout   string    `json:"stdout,omitempty"`
+	Stderr   string    `json:"stderr,omitempty"`
+	Err      string    `json:"error,omitempty"`
+}
+
+// Duration returns how long the command ran
+func (inv Invocation) Duration() time.Duration {
+	return inv.End.Sub(inv.Start)
+}
+
+// Command returns the command line
+func (inv Invocation) Command() string {
+	return "brew " + strings.Join(inv.Args, " ")
+}
+
+// Tracer records every brew command to a trace file of JSON lines. When the
+// file grows past its limit it is moved to <path>.1 and a new one is started.
+type Tracer struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	size   int64
+	lastID int
+	recent []Invocation
+}
+
+var (
+	tracerMu sync.RWMutex
+	tracer   *Tracer
+)
+
+// StartTracing records brew commands to the trace file at path until StopTracing
+func StartTracing(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create trace directory: %w", err)
+	}
+	t := &Tracer{path: path}
+	if err := t.open(); err != nil {
+		return err
+	}
+
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	if tracer != nil {
+		tracer.close()
+	}
+	tracer = t
+	return nil
+}
+
+// StopTracing stops recording brew commands and closes the trace file
+func StopTracing() {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	if tracer != nil {
+		tracer.close()
+		tracer = nil
+	}
+}
+
+// Tracing reports whether brew commands are being recorded, and to which file
+func Tracing() (string, bool) {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	if tracer == nil {
+		return "", false
+	}
+	return tracer.path, true
+}
+
+// Traces returns the most recent recorded commands, oldest first
+func Traces() []Invocation {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Invocation(nil), t.recent...)
+}
+
+// startTrace begins recording a command that is about to run. It returns nil
+// when tracing is off.
+func startTrace(cmd *exec.Cmd) *Invocation {
+	tracerMu.RLock()
+	enabled := tracer != nil
+	tracerMu.RUnlock()
+	if !enabled {
+		return nil
+	}
+
+	inv := &Invocation{Args: cmd.Args[1:], Dir: cmd.Dir, Start: time.Now(), ExitCode: -1}
+	if inv.Dir == "" {
+		inv.Dir, _ = os.Getwd()
+	}
+	for _, env := range cmd.Environ() {
+		if strings.HasPrefix(env, "HOMEBREW_") {
+			inv.Env = append(inv.Env, maskEnv(env))
+		}
+	}
+	return inv
+}
+
+// secretEnvNames are parts of variable names whose values are credentials,
+// like HOMEBREW_GITHUB_API_TOKEN
+var secretEnvNames = []string{"TOKEN", "KEY", "PASSWORD", "SECRET", "AUTH", "CREDENTIAL"}
+
+// maskEnv hides the value of a NAME=value variable that holds a secret, and
+// the user info of URLs like the ones in HOMEBREW_*_PROXY
+func maskEnv(env string) string {
+	name, value, _ := strings.Cut(env, "=")
+	for _, secret := range secretEnvNames {
+		if strings.Contains(name, secret) {
+			return name + "=***"
+		}
+	}
+	// URLs may have a scheme or not, like user:password@proxy:3128
+	scheme, rest := "", value
+	if i := strings.Index(value, "://"); i >= 0 {
+		scheme, rest = value[:i+3], value[i+3:]
+	}
+	authority := rest
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		authority = rest[:i]
+	}
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		return name + "=" + scheme + "***" + rest[i:]
+	}
+	return env
+}
+
+// finish records the result of a traced command
+func (inv *Invocation) finish(cmd *exec.Cmd, stdout, stderr string, err error) {
+	if inv == nil {
+		return
+	}
+	inv.End = time.Now()
+	if cmd.ProcessState != nil {
+		inv.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	inv.Stdout = truncateOutput(stdout)
+	inv.Stderr = truncateOutput(stderr)
+	if err != nil {
+		inv.Err = err.Error()
+	}
+
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+	if t != nil {
+		t.record(*inv)
+	}
+}
+
+func (t *Tracer) record(inv Invocation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastID++
+	inv.ID = t.lastID
+	t.recent = append(t.recent, inv)
+	if len(t.recent) > maxTraceRecent {
+		t.recent = t.recent[len(t.recent)-maxTraceRecent:]
+	}
+
+	if t.file == nil {
+		return
+	}
+	line, err := json.Marshal(inv)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	if t.size+int64(len(line)) > maxTraceFileSize {
+		t.rotate()
+	}
+	if t.file == nil {
+		return
+	}
+	n, _ := t.file.Write(line)
+	t.size += int64(n)
+}
+
+func (t *Tracer) open() error {
+	// Commands and their output can be private, keep them to the user
+	file, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	t.file, t.size = file, info.Size()
+	return nil
+}
+
+// rotate moves the trace file to <path>.1, replacing the previous one. If a new
+// file can't be opened the trace keeps going in memory only.
+func (t *Tracer) rotate() {
+	t.close()
+	_ = os.Rename(t.path, t.path+".1")
+	_ = t.open()
+}
+
+func (t *Tracer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// truncateOutput keeps the start of long command output
+func truncateOutput(s string) string {
+	if len(s) <= maxTraceOutput {
+		return s
+	}
+	return s[:maxTraceOutput] + fmt.Sprintf("\n... (%d bytes truncated)", len(s)-maxTraceOutput)
+}
+
+// limitedBuffer collects streamed output for the trace, up to a limit
+type limitedBuffer struct {
+	strings.Builder
+	dropped int
+}
+
+func (b *limitedBuffer) add(line string) {
+	if b.Len()+len(line)+1 > maxTraceOutput {
+		b.dropped += len(line) + 1
+		return
+	}
+	b.WriteString(line)
+	b.WriteByte('\n')
+}
+
+func (b *limitedBuffer) String() string {
+	if b.dropped == 0 {
+		return b.Builder.String()
+	}
+	return b.Builder.String() + fmt.Sprintf("... (%d bytes truncated)", b.dropped)
+}
@@ -17,27 +17,80 @@ type Package struct {
 	Version     string      `json:"version"`
 	Description string      `json:"desc"`
 	Homepage    string      `json:"homepage"`
+	Tap         string      `json:"tap"`
 	Type        PackageType `json:"-"`
 	Installed   bool        `json:"-"`
 	Outdated    bool        `json:"-"`
 	Pinned      bool        `json:"-"`
+	Deprecated  bool        `json:"-"` // Deprecated or disabled upstream
+	Size        int64       `json:"-"` // On-disk size in bytes, 0 when unknown
+
+	InstalledVersions []string `json:"-"` // Every installed version, oldest first
+	LinkedVersion     string   `json:"-"` // Version linked into the prefix, formulae only
+
+	// Inventory data from brew info --json=v2
+	License      string   `json:"license"` // SPDX expression, formulae only
+	SourceURL    string   `json:"-"`       // Download URL of the installed version, empty when unknown
+	Checksum     string   `json:"-"`       // SHA-256 of the download
+	Dependencies []string `json:"-"`       // Runtime formula dependencies of the installed version
+
+	CaskDependencies []string `json:"-"` // Casks a cask depends on
 }
 
 // PackageInfo represents detailed information about a package
 type PackageInfo struct {
 	Package
-	Dependencies []string  `json:"dependencies"`
-	BuildDeps    []string  `json:"build_dependencies"`
-	Caveats      string    `json:"caveats"`
-	InstallDate  time.Time `json:"-"`
+	Dependencies []string        `json:"dependencies"`
+	BuildDeps    []string        `json:"build_dependencies"`
+	Caveats      string          `json:"caveats"`
+	InstallDate  time.Time       `json:"-"`                    // When the current version was installed, zero when unknown
+	OnRequest    bool            `json:"installed_on_request"` // Installed by the user rather than as a dependency
+	FromBottle   bool            `json:"poured_from_bottle"`   // Poured from a bottle rather than built from source
+	Conflicts    []string        `json:"conflicts_with"`
+	Options      []FormulaOption `json:"options"`
+	HasHead      bool            `json:"-"` // The formula can be installed with --HEAD
+}
+
+// FormulaOption is a build option a formula accepts, like --with-debug
+type FormulaOption struct {
+	Flag        string `json:"option"`
+	Description string `json:"description"`
 }
 
 // OutdatedPackage represents a package that has an available update
 type OutdatedPackage struct {
-	Name           string `json:"name"`
-	CurrentVersion string `json:"installed_versions"`
-	LatestVersion  string `json:"current_version"`
-	Pinned         bool   `json:"pinned"`
+	Name              string      `json:"name"`
+	Type              PackageType `json:"type"`
+	CurrentVersion    string      `json:"installed_version"` // Newest installed version, brew calls the latest one current_version
+	InstalledVersions []string    `json:"installed_versions"`
+	LatestVersion     string      `json:"latest_version"`
+	Pinned            bool        `json:"pinned"`
+	PinnedVersion     string      `json:"pinned_version,omitempty"`
+	AutoUpdates       bool        `json:"auto_updates"` // Cask only reported outdated with --greedy
+	Update            UpdateKind  `json:"update"`       // How big the jump from CurrentVersion to LatestVersion is
+}
+
+// KegUsage is the on-disk size of one installed version of a package
+type KegUsage struct {
+	Version string
+	Size    int64
+	Current bool // Linked keg for formulae, newest version for casks
+}
+
+// DiskUsage is the on-disk size of a Cellar rack or Caskroom entry
+type DiskUsage struct {
+	Name        string
+	Type        PackageType
+	Versions    []KegUsage
+	Size        int64 // All versions
+	Reclaimable int64 // Versions other than the current one
+}
+
+// CleanupItem is something cleanup or autoremove would remove
+type CleanupItem struct {
+	Name string // Package the item belongs to, empty when brew cleanup can't target it
+	Path string // File or directory, empty for autoremoved formulae
+	Size int64  // Size in bytes, 0 when unknown
 }
 
 // Tap represents a Homebrew tap (third-party repository)
@@ -47,10 +100,47 @@ type Tap struct {
 	Remote   string
 }
 
-// InstallOptions represents options for installing packages
+// InstallOptions represents options for installing packages. They are
+// saved in the config to reinstall and upgrade a package the same way.
 type InstallOptions struct {
-	Cask  bool
-	Force bool
+	Cask  bool `json:"cask,omitempty"`
+	Force bool `json:"-"`
+
+	HEAD               bool     `json:"head,omitempty"` // Install the development version
+	BuildFromSource    bool     `json:"build_from_source,omitempty"`
+	ForceBottle        bool     `json:"force_bottle,omitempty"`
+	IgnoreDependencies bool     `json:"ignore_dependencies,omitempty"`
+	SkipPostInstall    bool     `json:"skip_post_install,omitempty"`
+	Flags              []string `json:"flags,omitempty"` // Formula options from brew options, like --with-debug
+}
+
+// Customized reports whether the options change how a package is built or
+// installed, as opposed to which package is installed
+func (o InstallOptions) Customized() bool {
+	return o.Upgrade() != (UpgradeOptions{}) || o.IgnoreDependencies || o.SkipPostInstall || len(o.Flags) > 0
+}
+
+// Upgrade returns the options to upgrade a package installed with o
+func (o InstallOptions) Upgrade() UpgradeOptions {
+	return UpgradeOptions{
+		BuildFromSource: o.BuildFromSource,
+		ForceBottle:     o.ForceBottle,
+		FetchHEAD:       o.HEAD,
+	}
+}
+
+// LinkOptions represents options for linking a formula
+type LinkOptions struct {
+	Overwrite bool // Replace files in the prefix that belong to no formula
+	DryRun    bool // List what would be linked without linking it
+}
+
+// UpgradeOptions represents options for upgrading packages
+type UpgradeOptions struct {
+	Greedy          bool // Also upgrade casks that update themselves
+	BuildFromSource bool
+	ForceBottle     bool
+	FetchHEAD       bool // Upgrade HEAD installs to the latest commit
 }
 
 // UninstallOptions represents options for uninstalling packages
@@ -0,0 +1,236 @@
+package brew
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version is a parsed Homebrew version string. Homebrew versions are mostly
+// dotted numbers but can carry letters ("1.1.1w"), pre-release tags
+// ("2.0rc1"), dates ("2024-01-15") and a revision suffix ("1.7_1").
+type Version struct {
+	Raw      string
+	Segments []string // Runs of digits or letters, separators dropped
+	Revision int      // Homebrew rebuild number after "_", 0 when absent
+}
+
+// ParseVersion parses a Homebrew version string
+func ParseVersion(s string) Version {
+	v := Version{Raw: s}
+
+	base := strings.TrimSpace(s)
+	if i := strings.LastIndex(base, "_"); i != -1 {
+		if revision, err := strconv.Atoi(base[i+1:]); err == nil {
+			v.Revision = revision
+			base = base[:i]
+		}
+	}
+	base = strings.TrimPrefix(strings.TrimPrefix(base, "v"), "V")
+
+	var current []rune
+	currentDigit := false
+	flush := func() {
+		if len(current) > 0 {
+			v.Segments = append(v.Segments, string(current))
+			current = nil
+		}
+	}
+	for _, r := range base {
+		switch {
+		case unicode.IsDigit(r):
+			if !currentDigit {
+				flush()
+			}
+			currentDigit = true
+			current = append(current, r)
+		case unicode.IsLetter(r):
+			if currentDigit {
+				flush()
+			}
+			currentDigit = false
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return v
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than other
+func (v Version) Compare(other Version) int {
+	if c := v.CompareUpstream(other); c != 0 {
+		return c
+	}
+	return compareInts(v.Revision, other.Revision)
+}
+
+// CompareUpstream compares versions ignoring Homebrew revisions
+func (v Version) CompareUpstream(other Version) int {
+	for i := 0; i < len(v.Segments) || i < len(other.Segments); i++ {
+		if c := compareSegments(segmentAt(v.Segments, i), segmentAt(other.Segments, i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Numeric returns the numeric value of segment i, and whether it is numeric
+func (v Version) Numeric(i int) (int, bool) {
+	if i >= len(v.Segments) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v.Segments[i])
+	return n, err == nil
+}
+
+// CompareVersions compares two Homebrew version strings, see Version.Compare
+func CompareVersions(a, b string) int {
+	return ParseVersion(a).Compare(ParseVersion(b))
+}
+
+// newestVersion returns the highest of versions, "" when there are none.
+// brew doesn't list installed kegs in version order.
+func newestVersion(versions []string) string {
+	newest := ""
+	for _, version := range versions {
+		if newest == "" || CompareVersions(version, newest) > 0 {
+			newest = version
+		}
+	}
+	return newest
+}
+
+// UpdateKind classifies the jump between two versions
+type UpdateKind string
+
+const (
+	UpdateMajor    UpdateKind = "major"
+	UpdateMinor    UpdateKind = "minor"
+	UpdatePatch    UpdateKind = "patch"
+	UpdateRevision UpdateKind = "revision" // Same upstream version, new Homebrew build
+	UpdateOther    UpdateKind = "other"    // Date-based or otherwise not semver-like
+)
+
+// rank orders kinds by how disruptive they are, other counting as major
+func (k UpdateKind) rank() int {
+	switch k {
+	case UpdateRevision:
+		return 0
+	case UpdatePatch:
+		return 1
+	case UpdateMinor:
+		return 2
+	}
+	return 3
+}
+
+// AtMost reports whether k is no more disruptive than limit
+func (k UpdateKind) AtMost(limit UpdateKind) bool {
+	return k.rank() <= limit.rank()
+}
+
+// ClassifyUpdate classifies an update by the first version segment that
+// changes: the first is major, the second minor, anything later patch.
+// Versions starting with a year or a date like "20240115" are classified
+// as other, since their segments don't carry semver meaning.
+func ClassifyUpdate(from, to string) UpdateKind {
+	a, b := ParseVersion(from), ParseVersion(to)
+	if a.CompareUpstream(b) == 0 {
+		if a.Revision != b.Revision {
+			return UpdateRevision
+		}
+		return UpdatePatch
+	}
+	if isDateLike(a) || isDateLike(b) {
+		return UpdateOther
+	}
+
+	for i := 0; i < len(a.Segments) || i < len(b.Segments); i++ {
+		if compareSegments(segmentAt(a.Segments, i), segmentAt(b.Segments, i)) == 0 {
+			continue
+		}
+		switch i {
+		case 0:
+			return UpdateMajor
+		case 1:
+			return UpdateMinor
+		default:
+			return UpdatePatch
+		}
+	}
+	return UpdatePatch
+}
+
+// isDateLike reports whether a version starts with a year or a full date
+func isDateLike(v Version) bool {
+	n, ok := v.Numeric(0)
+	return ok && n >= 1990
+}
+
+func segmentAt(segments []string, i int) string {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return ""
+}
+
+// compareSegments orders segments: numbers by value, a missing segment before
+// a non-zero number ("1.0" < "1.0.1", "1.0" == "1.0.0") or letter suffix ("1.1.1" < "1.1.1w") but after
+// a pre-release tag ("1.0rc1" < "1.0"), and letters alphabetically
+func compareSegments(a, b string) int {
+	if a == b {
+		return 0
+	}
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	aIsNum, bIsNum := aErr == nil, bErr == nil
+
+	switch {
+	case aIsNum && bIsNum:
+		return compareInts(aNum, bNum)
+	case a == "":
+		if bIsNum {
+			return compareInts(0, bNum)
+		}
+		if isPrerelease(b) {
+			return 1
+		}
+		return -1
+	case b == "":
+		if aIsNum {
+			return compareInts(aNum, 0)
+		}
+		if isPrerelease(a) {
+			return -1
+		}
+		return 1
+	case aIsNum:
+		return 1
+	case bIsNum:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// isPrerelease reports whether a letter segment marks a pre-release
+func isPrerelease(segment string) bool {
+	switch segment {
+	case "alpha", "beta", "rc", "pre", "preview", "dev", "snapshot":
+		return true
+	}
+	return false
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,197 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Event identifies when a hook runs
+type Event string
+
+const (
+	PreInstall    Event = "pre_install"
+	PostInstall   Event = "post_install"
+	PreUpgrade    Event = "pre_upgrade"
+	PostUpgrade   Event = "post_upgrade"
+	PostUninstall Event = "post_uninstall"
+	OnFailure     Event = "on_failure"
+)
+
+// defaultTimeout limits how long a single hook may run
+const defaultTimeout = 60 * time.Second
+
+// Config holds the hook commands, run with sh -c
+type Config struct {
+	PreInstall    string `json:"pre_install,omitempty"`
+	PostInstall   string `json:"post_install,omitempty"`
+	PreUpgrade    string `json:"pre_upgrade,omitempty"`
+	PostUpgrade   string `json:"post_upgrade,omitempty"`
+	PostUninstall string `json:"post_uninstall,omitempty"`
+	OnFailure     string `json:"on_failure,omitempty"`
+
+	AbortOnFailure bool `json:"abort_on_failure"` // A failing pre hook cancels the operation
+	Timeout        int  `json:"timeout"`          // seconds per hook, 0 uses the default
+}
+
+// command returns the command configured for an event
+func (c Config) command(event Event) string {
+	switch event {
+	case PreInstall:
+		return c.PreInstall
+	case PostInstall:
+		return c.PostInstall
+	case PreUpgrade:
+		return c.PreUpgrade
+	case PostUpgrade:
+		return c.PostUpgrade
+	case PostUninstall:
+		return c.PostUninstall
+	case OnFailure:
+		return c.OnFailure
+	}
+	return ""
+}
+
+// Operation describes the brew operation hooks run around
+type Operation struct {
+	Name        string   // install, upgrade or uninstall
+	Packages    []string // Empty for upgrade all
+	PackageType string   // formula or cask
+	FromVersion string   // Installed version before the operation
+	ToVersion   string   // Version after the operation
+}
+
+// events returns the pre and post hooks for the operation
+func (op Operation) events() (pre, post Event) {
+	switch op.Name {
+	case "install":
+		return PreInstall, PostInstall
+	case "upgrade":
+		return PreUpgrade, PostUpgrade
+	case "uninstall":
+		return "", PostUninstall
+	}
+	return "", ""
+}
+
+// Runner runs hook commands around operations
+type Runner struct {
+	config Config
+}
+
+// NewRunner creates a hook runner
+func NewRunner(config Config) *Runner {
+	return &Runner{config: config}
+}
+
+// Wrap runs fn between the operation's pre and post hooks, and the on_failure
+// hook if it fails. It returns the hooks' output as log lines.
+func (r *Runner) Wrap(ctx context.Context, op Operation, fn func() error) ([]string, error) {
+	var logs []string
+	pre, post := op.events()
+
+	if pre != "" {
+		output, err := r.Run(ctx, pre, op, nil)
+		logs = append(logs, output...)
+		if err != nil {
+			if r.config.AbortOnFailure {
+				err = fmt.Errorf("%s hook failed, %s aborted: %w", pre, op.Name, err)
+				logs = append(logs, r.runFailure(ctx, op, err)...)
+				return logs, err
+			}
+			logs = append(logs, fmt.Sprintf("[%s] failed: %v", pre, err))
+		}
+	}
+
+	if err := fn(); err != nil {
+		logs = append(logs, r.runFailure(ctx, op, err)...)
+		return logs, err
+	}
+
+	if post != "" {
+		output, err := r.Run(ctx, post, op, nil)
+		logs = append(logs, output...)
+		if err != nil {
+			logs = append(logs, fmt.Sprintf("[%s] failed: %v", post, err))
+		}
+	}
+
+	return logs, nil
+}
+
+func (r *Runner) runFailure(ctx context.Context, op Operation, opErr error) []string {
+	output, err := r.Run(ctx, OnFailure, op, opErr)
+	if err != nil {
+		output = append(output, fmt.Sprintf("[%s] failed: %v", OnFailure, err))
+	}
+	return output
+}
+
+// Run runs the hook for an event, if one is configured. Each output line is
+// prefixed with the event name.
+func (r *Runner) Run(ctx context.Context, event Event, op Operation, opErr error) ([]string, error) {
+	command := strings.TrimSpace(r.config.command(event))
+	if command == "" {
+		return nil, nil
+	}
+
+	timeout := defaultTimeout
+	if r.config.Timeout > 0 {
+		timeout = time.Duration(r.config.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), Environment(event, op, opErr)...)
+	output, err := cmd.CombinedOutput()
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, fmt.Sprintf("[%s] %s", event, line))
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return lines, fmt.Errorf("timed out after %s", timeout)
+	}
+	return lines, err
+}
+
+// Environment returns the BREWST_* variables passed to a hook
+func Environment(event Event, op Operation, opErr error) []string {
+	result := ""
+	if event != PreInstall && event != PreUpgrade {
+		result = "success"
+	}
+	errMsg := ""
+	if opErr != nil {
+		result = "failure"
+		errMsg = opErr.Error()
+	}
+
+	return []string{
+		"BREWST_HOOK=" + string(event),
+		"BREWST_OPERATION=" + op.Name,
+		"BREWST_PACKAGES=" + strings.Join(op.Packages, " "),
+		"BREWST_PACKAGE=" + firstOrEmpty(op.Packages),
+		"BREWST_PACKAGE_TYPE=" + op.PackageType,
+		"BREWST_FROM_VERSION=" + op.FromVersion,
+		"BREWST_TO_VERSION=" + op.ToVersion,
+		"BREWST_RESULT=" + result,
+		"BREWST_ERROR=" + errMsg,
+	}
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,111 @@
+package license
+
+import "strings"
+
+// expr is a parsed SPDX license expression: an AND/OR of operands, or a
+// single license identifier with an optional WITH exception
+type expr struct {
+	op        string // "AND", "OR" or "" for a license
+	operands  []*expr
+	id        string
+	exception string
+}
+
+// parse parses an SPDX expression, reporting false when it isn't one
+func parse(expression string) (*expr, bool) {
+	p := &parser{tokens: tokenize(expression)}
+	if len(p.tokens) == 0 {
+		return nil, false
+	}
+	node, ok := p.parseOr()
+	if !ok || p.pos != len(p.tokens) {
+		return nil, false
+	}
+	return node, true
+}
+
+// tokenize splits an expression into identifiers, operators and parentheses
+func tokenize(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+// operator returns the upper-case operator for a token, or ""
+func operator(token string) string {
+	switch upper := strings.ToUpper(token); upper {
+	case "AND", "OR", "WITH":
+		return upper
+	}
+	return ""
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// parseOr parses operands joined by OR, which binds loosest
+func (p *parser) parseOr() (*expr, bool) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*expr, bool) {
+	return p.parseBinary("AND", p.parseLicense)
+}
+
+func (p *parser) parseBinary(op string, operand func() (*expr, bool)) (*expr, bool) {
+	first, ok := operand()
+	if !ok {
+		return nil, false
+	}
+	node := &expr{op: op, operands: []*expr{first}}
+	for operator(p.peek()) == op {
+		p.pos++
+		next, ok := operand()
+		if !ok {
+			return nil, false
+		}
+		node.operands = append(node.operands, next)
+	}
+	if len(node.operands) == 1 {
+		return first, true
+	}
+	return node, true
+}
+
+// parseLicense parses a parenthesized expression or "id [WITH exception]"
+func (p *parser) parseLicense() (*expr, bool) {
+	token := p.peek()
+	switch {
+	case token == "(":
+		p.pos++
+		node, ok := p.parseOr()
+		if !ok || p.peek() != ")" {
+			return nil, false
+		}
+		p.pos++
+		return node, true
+	case token == "" || token == ")" || operator(token) != "":
+		return nil, false
+	}
+
+	p.pos++
+	node := &expr{id: token}
+	if operator(p.peek()) == "WITH" {
+		p.pos++
+		exception := p.peek()
+		if exception == "" || exception == "(" || exception == ")" || operator(exception) != "" {
+			return nil, false
+		}
+		p.pos++
+		node.exception = exception
+	}
+	return node, true
+}
@@ -0,0 +1,173 @@
+package license
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/lazar0169/brewst/internal/brew"
+)
+
+// Unknown labels packages without license data, like casks
+const Unknown = "Unknown"
+
+// Policy lists licenses that may not be installed. Entries are SPDX
+// identifiers or glob patterns like "AGPL-*", matched case-insensitively.
+type Policy struct {
+	Disallowed []string `json:"disallowed"`
+}
+
+// Enabled reports whether the policy disallows anything
+func (p Policy) Enabled() bool {
+	return len(p.Disallowed) > 0
+}
+
+// Violations returns the disallowed licenses a package with the expression
+// can't avoid. "MIT OR GPL-3.0-only" is fine when only GPL is disallowed,
+// since the MIT option can be chosen, but "MIT AND GPL-3.0-only" is not.
+func (p Policy) Violations(expression string) []string {
+	if !p.Enabled() || strings.TrimSpace(expression) == "" {
+		return nil
+	}
+	node, ok := parse(expression)
+	if !ok {
+		// Homebrew writes non-SPDX licenses like "Public Domain" as plain text
+		node = &expr{id: strings.TrimSpace(expression)}
+	}
+	_, violations := p.evaluate(node)
+	return dedupe(violations)
+}
+
+// Allows reports whether the expression satisfies the policy
+func (p Policy) Allows(expression string) bool {
+	return len(p.Violations(expression)) == 0
+}
+
+// matches reports whether a license identifier is disallowed
+func (p Policy) matches(id string) bool {
+	id = strings.ToLower(id)
+	for _, pattern := range p.Disallowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == id {
+			return true
+		}
+		if ok, err := path.Match(pattern, id); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate returns whether the node is allowed and the licenses that aren't
+func (p Policy) evaluate(node *expr) (bool, []string) {
+	switch node.op {
+	case "OR":
+		var violations []string
+		for _, operand := range node.operands {
+			ok, v := p.evaluate(operand)
+			if ok {
+				return true, nil
+			}
+			violations = append(violations, v...)
+		}
+		return false, violations
+	case "AND":
+		allowed := true
+		var violations []string
+		for _, operand := range node.operands {
+			ok, v := p.evaluate(operand)
+			allowed = allowed && ok
+			violations = append(violations, v...)
+		}
+		return allowed, violations
+	}
+
+	// Exceptions only grant extra permissions, so only the license counts
+	if p.matches(node.id) {
+		return false, []string{node.id}
+	}
+	return true, nil
+}
+
+// Group is a set of packages sharing a license expression
+type Group struct {
+	Expression string
+	Packages   []brew.Package
+	Violations []string // Disallowed licenses, empty when allowed
+}
+
+// GroupPackages groups packages by license expression, largest groups first
+func GroupPackages(packages []brew.Package, policy Policy) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, pkg := range packages {
+		expression := Normalize(pkg.License)
+		if expression == "" {
+			expression = Unknown
+		}
+		i, ok := index[expression]
+		if !ok {
+			i = len(groups)
+			index[expression] = i
+			group := Group{Expression: expression}
+			if expression != Unknown {
+				group.Violations = policy.Violations(expression)
+			}
+			groups = append(groups, group)
+		}
+		groups[i].Packages = append(groups[i].Packages, pkg)
+	}
+
+	for i := range groups {
+		sort.Slice(groups[i].Packages, func(a, b int) bool {
+			return groups[i].Packages[a].Name < groups[i].Packages[b].Name
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		// Unknown last, it isn't a license
+		if (groups[i].Expression == Unknown) != (groups[j].Expression == Unknown) {
+			return groups[j].Expression == Unknown
+		}
+		if len(groups[i].Packages) != len(groups[j].Packages) {
+			return len(groups[i].Packages) > len(groups[j].Packages)
+		}
+		return groups[i].Expression < groups[j].Expression
+	})
+	return groups
+}
+
+// Normalize upper-cases the operators of an SPDX expression, since Homebrew
+// writes them in lower case ("MIT or Apache-2.0")
+func Normalize(expression string) string {
+	tokens := tokenize(expression)
+	if len(tokens) == 0 {
+		return ""
+	}
+	if _, ok := parse(expression); !ok {
+		return strings.TrimSpace(expression)
+	}
+
+	var b strings.Builder
+	for i, token := range tokens {
+		if i > 0 && token != ")" && tokens[i-1] != "(" {
+			b.WriteByte(' ')
+		}
+		if op := operator(token); op != "" {
+			token = op
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/state"
+	"gopkg.in/yaml.v3"
+)
+
+// Entry is a package the team requires, with optional version bounds
+type Entry struct {
+	Name string `json:"name" yaml:"name"`
+	Cask bool   `json:"cask,omitempty" yaml:"cask,omitempty"`
+	Min  string `json:"min,omitempty" yaml:"min,omitempty"` // Lowest allowed version
+	Max  string `json:"max,omitempty" yaml:"max,omitempty"` // Highest allowed version, "1.7" allows any 1.7.x
+}
+
+// Manifest declares the packages and taps every machine should have
+type Manifest struct {
+	Taps     []string `json:"taps" yaml:"taps"`
+	Packages []Entry  `json:"packages" yaml:"packages"`
+
+	Path string `json:"-" yaml:"-"` // File the manifest was loaded from
+}
+
+// Status is how an installed package or tap compares to its entry
+type Status int
+
+const (
+	StatusCompliant Status = iota
+	StatusMissing
+	StatusTooOld
+	StatusTooNew
+)
+
+// String returns the status label
+func (s Status) String() string {
+	switch s {
+	case StatusMissing:
+		return "missing"
+	case StatusTooOld:
+		return "too old"
+	case StatusTooNew:
+		return "too new"
+	default:
+		return "compliant"
+	}
+}
+
+// Result is the drift of one manifest entry
+type Result struct {
+	Name      string
+	Kind      string // "formula", "cask" or "tap"
+	Installed string // Installed version, "" when missing or for taps
+	Entry     Entry
+	Status    Status
+}
+
+// Load reads a JSON or YAML manifest, by file extension. An empty path means no manifest.
+func Load(file string) (*Manifest, error) {
+	if file == "" {
+		return nil, nil
+	}
+	file = state.ExpandHome(file)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team manifest: %w", err)
+	}
+
+	var m Manifest
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &m)
+	default:
+		err = json.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse team manifest %s: %w", file, err)
+	}
+
+	for _, entry := range m.Packages {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("team manifest %s has a package without a name", file)
+		}
+		if entry.Min != "" && entry.Max != "" && !withinMax(brew.ParseVersion(entry.Min), entry.Max) {
+			return nil, fmt.Errorf("team manifest %s: %s has min %s above max %s", file, entry.Name, entry.Min, entry.Max)
+		}
+	}
+	m.Path = file
+	return &m, nil
+}
+
+// Evaluate compares the manifest to the installed packages and taps
+func (m *Manifest) Evaluate(packages []brew.Package, taps []brew.Tap) []Result {
+	if m == nil {
+		return nil
+	}
+
+	tapped := make(map[string]bool, len(taps))
+	for _, tap := range taps {
+		tapped[strings.ToLower(tap.Name)] = true
+	}
+	installed := make(map[string]brew.Package, len(packages))
+	for _, pkg := range packages {
+		installed[string(pkg.Type)+"/"+pkg.Name] = pkg
+	}
+
+	results := make([]Result, 0, len(m.Taps)+len(m.Packages))
+	for _, tap := range m.Taps {
+		result := Result{Name: tap, Kind: "tap"}
+		if !tapped[strings.ToLower(tap)] {
+			result.Status = StatusMissing
+		}
+		results = append(results, result)
+	}
+
+	for _, entry := range m.Packages {
+		pkgType := entryType(entry)
+		result := Result{Name: entry.Name, Kind: string(pkgType), Entry: entry}
+		pkg, ok := installed[string(pkgType)+"/"+entry.Name]
+		if !ok {
+			result.Status = StatusMissing
+		} else {
+			result.Installed = pkg.Version
+			result.Status = entry.Check(pkg.Version)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Entry returns the manifest entry for a package, if it has one
+func (m *Manifest) Entry(name string, pkgType brew.PackageType) (Entry, bool) {
+	if m == nil {
+		return Entry{}, false
+	}
+	for _, entry := range m.Packages {
+		if entry.Name == name && entryType(entry) == pkgType {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Check compares a version to the entry's bounds. Homebrew revisions are
+// ignored, so "1.7_1" satisfies max "1.7".
+func (e Entry) Check(version string) Status {
+	v := brew.ParseVersion(version)
+	if e.Min != "" && v.CompareUpstream(brew.ParseVersion(e.Min)) < 0 {
+		return StatusTooOld
+	}
+	if e.Max != "" && !withinMax(v, e.Max) {
+		return StatusTooNew
+	}
+	return StatusCompliant
+}
+
+// withinMax reports whether v is at most max, comparing only as many
+// segments as max has so that max "1.7" includes "1.7.3"
+func withinMax(v brew.Version, max string) bool {
+	bound := brew.ParseVersion(max)
+	truncated := v
+	if len(truncated.Segments) > len(bound.Segments) {
+		truncated.Segments = truncated.Segments[:len(bound.Segments)]
+	}
+	return truncated.CompareUpstream(bound) <= 0
+}
+
+func entryType(entry Entry) brew.PackageType {
+	if entry.Cask {
+		return brew.TypeCask
+	}
+	return brew.TypeFormula
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/state"
+)
+
+// Content types for the two supported exposition formats
+const (
+	ContentTypePrometheus  = "text/plain; version=0.0.4; charset=utf-8"
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Snapshot is the package state metrics are rendered from
+type Snapshot struct {
+	Summary    state.Summary
+	LastUpdate time.Time // Zero when brew update never ran
+}
+
+// Collect loads installed, outdated and disk usage data into a state, the
+// same way the TUI does, and summarizes it
+func Collect(ctx context.Context, client brew.Client, greedy bool) (*Snapshot, error) {
+	appState := state.NewState()
+
+	installed, err := client.ListInstalled(ctx, true, true)
+	if err != nil {
+		return nil, err
+	}
+	appState.SetInstalled(installed)
+
+	outdated, err := client.Outdated(ctx, greedy)
+	if err != nil {
+		return nil, err
+	}
+	appState.SetOutdated(outdated)
+
+	usage, err := client.DiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appState.SetDiskUsage(usage)
+
+	// A missing update timestamp only drops that metric
+	lastUpdate, _ := client.LastUpdate(ctx)
+
+	return &Snapshot{Summary: appState.GetSummary(), LastUpdate: lastUpdate}, nil
+}
+
+// Write writes the snapshot as Prometheus text, or OpenMetrics text when openMetrics is set
+func Write(w io.Writer, snapshot *Snapshot, now time.Time, openMetrics bool) error {
+	out := bufio.NewWriter(w)
+	summary := snapshot.Summary
+
+	gauge(out, "brewst_installed_packages", "Installed packages by type.")
+	for _, t := range []brew.PackageType{brew.TypeFormula, brew.TypeCask} {
+		sample(out, "brewst_installed_packages", `type="`+string(t)+`"`, float64(summary.Installed[t]))
+	}
+
+	gauge(out, "brewst_outdated_packages", "Packages with an update available by type.")
+	for _, t := range []brew.PackageType{brew.TypeFormula, brew.TypeCask} {
+		sample(out, "brewst_outdated_packages", `type="`+string(t)+`"`, float64(summary.Outdated[t]))
+	}
+
+	gauge(out, "brewst_pinned_packages", "Pinned formulae.")
+	sample(out, "brewst_pinned_packages", "", float64(summary.Pinned))
+
+	gauge(out, "brewst_deprecated_packages", "Installed packages that are deprecated or disabled upstream.")
+	sample(out, "brewst_deprecated_packages", "", float64(summary.Deprecated))
+
+	gauge(out, "brewst_disk_usage_bytes", "Disk space used by installed packages, including old versions.")
+	sample(out, "brewst_disk_usage_bytes", `location="cellar"`, float64(summary.DiskUsage[brew.TypeFormula]))
+	sample(out, "brewst_disk_usage_bytes", `location="caskroom"`, float64(summary.DiskUsage[brew.TypeCask]))
+
+	gauge(out, "brewst_reclaimable_bytes", "Disk space used by old versions that cleanup would remove.")
+	sample(out, "brewst_reclaimable_bytes", "", float64(summary.Reclaimable))
+
+	if !snapshot.LastUpdate.IsZero() {
+		gauge(out, "brewst_seconds_since_last_update", "Seconds since brew update last fetched package data.")
+		sample(out, "brewst_seconds_since_last_update", "", now.Sub(snapshot.LastUpdate).Seconds())
+	}
+
+	if openMetrics {
+		fmt.Fprintln(out, "# EOF")
+	}
+	return out.Flush()
+}
+
+func gauge(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+}
+
+func sample(w io.Writer, name, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s %g\n", name, value)
+}
+
+// Handler serves metrics. Collecting runs several brew commands and walks the
+// Cellar, so results are reused for ttl between scrapes.
+func Handler(client brew.Client, greedy bool, ttl time.Duration) http.Handler {
+	var mu sync.Mutex
+	var cached *Snapshot
+	var collectedAt time.Time
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if cached == nil || time.Since(collectedAt) > ttl {
+			snapshot, err := Collect(r.Context(), client, greedy)
+			if err != nil {
+				mu.Unlock()
+				http.Error(w, "collecting metrics: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			cached = snapshot
+			collectedAt = time.Now()
+		}
+		snapshot := cached
+		mu.Unlock()
+
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		var buf bytes.Buffer
+		if err := Write(&buf, snapshot, time.Now(), openMetrics); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if openMetrics {
+			w.Header().Set("Content-Type", ContentTypeOpenMetrics)
+		} else {
+			w.Header().Set("Content-Type", ContentTypePrometheus)
+		}
+		_, _ = w.Write(buf.Bytes())
+	})
+}
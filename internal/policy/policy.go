@@ -0,0 +1,176 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/state"
+)
+
+// Rules lists packages and taps by name or glob pattern like "*-nightly".
+// Packages match by name ("jq") or full name ("user/tap/jq").
+type Rules struct {
+	Packages []string `json:"packages"`
+	Taps     []string `json:"taps"`
+}
+
+// Policy is a team install policy. Anything matching Deny is blocked. When
+// Allow lists packages or taps, anything else of that kind is blocked too,
+// except homebrew/core and homebrew/cask which only Deny can block.
+type Policy struct {
+	Allow   Rules  `json:"allow"`
+	Deny    Rules  `json:"deny"`
+	Message string `json:"message"` // Shown when something is blocked, e.g. who to ask
+
+	Path string `json:"-"` // File the policy was loaded from
+}
+
+// Decision is the result of checking a package or tap against the policy
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// Violation is an installed package or tap the policy doesn't allow
+type Violation struct {
+	Name   string
+	Kind   string // "formula", "cask" or "tap"
+	Reason string
+}
+
+// Load reads a policy file. An empty path means no policy.
+func Load(file string) (*Policy, error) {
+	if file == "" {
+		return nil, nil
+	}
+	file = state.ExpandHome(file)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install policy: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse install policy %s: %w", file, err)
+	}
+	p.Path = file
+	return &p, nil
+}
+
+// CheckPackage checks whether a package may be installed. The package's tap
+// must be allowed as well.
+func (p *Policy) CheckPackage(pkg brew.Package) Decision {
+	if p == nil {
+		return Decision{Allowed: true}
+	}
+
+	if d := p.CheckTap(packageTap(pkg)); !d.Allowed {
+		return d
+	}
+
+	names := []string{pkg.Name}
+	if pkg.FullName != "" && pkg.FullName != pkg.Name {
+		names = append(names, pkg.FullName)
+	}
+	if i := strings.LastIndex(pkg.Name, "/"); i != -1 {
+		names = append(names, pkg.Name[i+1:])
+	}
+	if rule, ok := match(p.Deny.Packages, names...); ok {
+		return Decision{Reason: fmt.Sprintf("%s is denied by the rule %q", pkg.Name, rule)}
+	}
+	if len(p.Allow.Packages) > 0 {
+		if _, ok := match(p.Allow.Packages, names...); !ok {
+			return Decision{Reason: fmt.Sprintf("%s is not on the list of allowed packages", pkg.Name)}
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// CheckTap checks whether a tap may be added
+func (p *Policy) CheckTap(tap string) Decision {
+	if p == nil {
+		return Decision{Allowed: true}
+	}
+
+	tap = strings.ToLower(tap)
+	if rule, ok := match(p.Deny.Taps, tap); ok {
+		return Decision{Reason: fmt.Sprintf("tap %s is denied by the rule %q", tap, rule)}
+	}
+	if len(p.Allow.Taps) > 0 && tap != "homebrew/core" && tap != "homebrew/cask" {
+		if _, ok := match(p.Allow.Taps, tap); !ok {
+			return Decision{Reason: fmt.Sprintf("tap %s is not on the list of allowed taps", tap)}
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// Violations returns the installed packages and taps the policy doesn't allow
+func (p *Policy) Violations(packages []brew.Package, taps []brew.Tap) []Violation {
+	if p == nil {
+		return nil
+	}
+
+	var violations []Violation
+	for _, tap := range taps {
+		if d := p.CheckTap(tap.Name); !d.Allowed {
+			violations = append(violations, Violation{Name: tap.Name, Kind: "tap", Reason: d.Reason})
+		}
+	}
+	for _, pkg := range packages {
+		if d := p.CheckPackage(pkg); !d.Allowed {
+			violations = append(violations, Violation{Name: pkg.Name, Kind: string(pkg.Type), Reason: d.Reason})
+		}
+	}
+	return violations
+}
+
+// Explain returns a message for a blocked operation, including the policy's
+// own message when it has one
+func (p *Policy) Explain(d Decision) string {
+	if d.Allowed || p == nil {
+		return ""
+	}
+	explanation := "Blocked by the team install policy: " + d.Reason + "."
+	if p.Message != "" {
+		explanation += "\n\n" + p.Message
+	}
+	return explanation
+}
+
+// packageTap returns the tap a package comes from. Search results only
+// carry the tap in their name, and core packages don't name one at all.
+func packageTap(pkg brew.Package) string {
+	if pkg.Tap != "" {
+		return pkg.Tap
+	}
+	for _, name := range []string{pkg.FullName, pkg.Name} {
+		if parts := strings.Split(name, "/"); len(parts) == 3 {
+			return parts[0] + "/" + parts[1]
+		}
+	}
+	if pkg.Type == brew.TypeCask {
+		return "homebrew/cask"
+	}
+	return "homebrew/core"
+}
+
+// match returns the first pattern matching any of the names, case-insensitively
+func match(patterns []string, names ...string) (string, bool) {
+	for _, pattern := range patterns {
+		lower := strings.ToLower(strings.TrimSpace(pattern))
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if lower == name {
+				return pattern, true
+			}
+			if ok, err := path.Match(lower, name); err == nil && ok {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+)
+
+// CycloneDX 1.5 JSON, see https://cyclonedx.org/docs/1.5/json/
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	Description        string           `json:"description,omitempty"`
+	Licenses           []cdxLicense     `json:"licenses,omitempty"`
+	PURL               string           `json:"purl,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cdxExternalRef struct {
+	Type   string    `json:"type"`
+	URL    string    `json:"url"`
+	Hashes []cdxHash `json:"hashes,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func writeCycloneDX(w io.Writer, doc Document) error {
+	index := newPackageIndex(doc.Packages)
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: doc.Timestamp.Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{
+				{Type: "application", Name: doc.Tool, Version: doc.Version},
+			}},
+			Component: cdxComponent{Type: "device", BOMRef: "device", Name: doc.Name},
+		},
+		Components:   make([]cdxComponent, 0, len(doc.Packages)),
+		Dependencies: make([]cdxDependency, 0, len(doc.Packages)),
+	}
+
+	for _, pkg := range doc.Packages {
+		bom.Components = append(bom.Components, cdxPackage(pkg))
+
+		dependsOn := []string{}
+		for _, dep := range index.dependencies(pkg) {
+			dependsOn = append(dependsOn, purl(dep))
+		}
+		bom.Dependencies = append(bom.Dependencies, cdxDependency{Ref: purl(pkg), DependsOn: dependsOn})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bom)
+}
+
+func cdxPackage(pkg brew.Package) cdxComponent {
+	component := cdxComponent{
+		Type:        "application",
+		BOMRef:      purl(pkg),
+		Name:        pkg.Name,
+		Version:     pkg.Version,
+		Description: pkg.Description,
+		PURL:        purl(pkg),
+		Properties: []cdxProperty{
+			{Name: "brew:type", Value: string(pkg.Type)},
+		},
+	}
+	if pkg.Tap != "" {
+		component.Properties = append(component.Properties, cdxProperty{Name: "brew:tap", Value: pkg.Tap})
+	}
+
+	if pkg.License != "" {
+		component.Licenses = []cdxLicense{{Expression: spdxLicense(pkg.License)}}
+	}
+
+	if isURL(pkg.Homepage) {
+		component.ExternalReferences = append(component.ExternalReferences,
+			cdxExternalRef{Type: "website", URL: pkg.Homepage})
+	}
+	if isURL(pkg.SourceURL) {
+		ref := cdxExternalRef{Type: "distribution", URL: pkg.SourceURL}
+		if isHexDigest(pkg.Checksum) {
+			ref.Hashes = []cdxHash{{Alg: "SHA-256", Content: pkg.Checksum}}
+		}
+		component.ExternalReferences = append(component.ExternalReferences, ref)
+	}
+
+	return component
+}
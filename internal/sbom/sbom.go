@@ -0,0 +1,185 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+)
+
+// Format is an SBOM output format
+type Format string
+
+const (
+	FormatCycloneDX Format = "cyclonedx"
+	FormatSPDX      Format = "spdx"
+)
+
+// ParseFormat parses a format name
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "cyclonedx", "cdx":
+		return FormatCycloneDX, nil
+	case "spdx":
+		return FormatSPDX, nil
+	}
+	return "", fmt.Errorf("unknown SBOM format %q (use cyclonedx or spdx)", name)
+}
+
+// Extension returns the conventional file extension for the format
+func (f Format) Extension() string {
+	if f == FormatSPDX {
+		return ".spdx.json"
+	}
+	return ".cdx.json"
+}
+
+// Document describes what the SBOM covers
+type Document struct {
+	Name      string // Usually the machine's hostname
+	Tool      string
+	Version   string
+	Timestamp time.Time
+	Packages  []brew.Package
+}
+
+// NewDocument creates a document for the installed packages on this machine
+func NewDocument(packages []brew.Package) Document {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "localhost"
+	}
+
+	sorted := append([]brew.Package(nil), packages...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type == brew.TypeFormula
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return Document{
+		Name:      hostname,
+		Tool:      "brewst",
+		Timestamp: time.Now().UTC(),
+		Packages:  sorted,
+	}
+}
+
+// WriteFile writes the document to a new file named after the document and
+// date in dir, and returns its path
+func WriteFile(dir string, doc Document, format Format) (string, error) {
+	name := fmt.Sprintf("brewst-sbom-%s-%s%s", doc.Name, doc.Timestamp.Format("20060102-150405"), format.Extension())
+	path := filepath.Join(dir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := Write(file, doc, format); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// Write writes the document in the given format
+func Write(w io.Writer, doc Document, format Format) error {
+	switch format {
+	case FormatCycloneDX:
+		return writeCycloneDX(w, doc)
+	case FormatSPDX:
+		return writeSPDX(w, doc)
+	}
+	return fmt.Errorf("unknown SBOM format %q", format)
+}
+
+// purl returns the package URL of an installed package
+func purl(pkg brew.Package) string {
+	p := "pkg:brew/" + purlEscape(pkg.Name)
+	if pkg.Version != "" {
+		p += "@" + purlEscape(pkg.Version)
+	}
+
+	var qualifiers []string
+	if pkg.Type == brew.TypeCask {
+		qualifiers = append(qualifiers, "type=cask")
+	}
+	if pkg.Tap != "" {
+		qualifiers = append(qualifiers, "tap="+url.QueryEscape(pkg.Tap))
+	}
+	if len(qualifiers) > 0 {
+		p += "?" + strings.Join(qualifiers, "&")
+	}
+	return p
+}
+
+// purlEscape percent-encodes a purl segment, including the "@" in names like python@3.12
+func purlEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// packageIndex finds packages by type and name, a formula and a cask can
+// share a name
+type packageIndex map[brew.PackageType]map[string]brew.Package
+
+func newPackageIndex(packages []brew.Package) packageIndex {
+	index := packageIndex{
+		brew.TypeFormula: make(map[string]brew.Package),
+		brew.TypeCask:    make(map[string]brew.Package),
+	}
+	for _, pkg := range packages {
+		index[pkg.Type][pkg.Name] = pkg
+	}
+	return index
+}
+
+// dependencies returns the installed packages pkg depends on
+func (index packageIndex) dependencies(pkg brew.Package) []brew.Package {
+	var deps []brew.Package
+	seen := make(map[string]bool)
+	add := func(pkgType brew.PackageType, names []string) {
+		for _, name := range names {
+			dep, ok := index[pkgType][name]
+			key := string(pkgType) + "/" + name
+			if !ok || seen[key] {
+				continue
+			}
+			seen[key] = true
+			deps = append(deps, dep)
+		}
+	}
+	add(brew.TypeFormula, pkg.Dependencies)
+	add(brew.TypeCask, pkg.CaskDependencies)
+	return deps
+}
+
+// isHexDigest reports whether s looks like a SHA-256 hex digest
+func isHexDigest(s string) bool {
+	return sha256Pattern.MatchString(s)
+}
+
+var sha256Pattern = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// isURL reports whether s is an absolute http(s) URL
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
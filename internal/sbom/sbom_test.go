@@ -0,0 +1,151 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// testDocument covers licenses, downloads, dependencies of both types and a
+// formula and a cask that share a name
+func testDocument() Document {
+	return Document{
+		Name:      "test-host",
+		Tool:      "brewst",
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Packages: []brew.Package{
+			{
+				Name:         "python@3.12",
+				Version:      "3.12.4_1",
+				Type:         brew.TypeFormula,
+				Tap:          "homebrew/core",
+				Description:  "Interpreted, interactive, object-oriented programming language",
+				License:      "Python-2.0",
+				SourceURL:    "https://www.python.org/ftp/python/3.12.4/Python-3.12.4.tgz",
+				Checksum:     "f6d419a6d8743ab26700801b4908d26d97e8b986e14f95de31b32de2b0e79554",
+				Dependencies: []string{"openssl@3", "docker"},
+			},
+			{Name: "openssl@3", Version: "3.3.1", Type: brew.TypeFormula, License: "Apache-2.0"},
+			{Name: "docker", Version: "27.0.3", Type: brew.TypeFormula, License: "Apache-2.0 AND (MIT OR GPL-2.0-only)"},
+			{Name: "docker", Version: "4.32.0", Type: brew.TypeCask, Tap: "homebrew/cask"},
+			{
+				Name:             "docker-tools",
+				Version:          "1.0",
+				Type:             brew.TypeCask,
+				License:          "Some custom terms",
+				Dependencies:     []string{"openssl@3"},
+				CaskDependencies: []string{"docker"},
+			},
+		},
+	}
+}
+
+// compileSchema compiles the schema with the given id from testdata, which
+// holds the official schemas and the ones they reference
+func compileSchema(t *testing.T, id string) *jsonschema.Schema {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, err := jsonschema.UnmarshalJSON(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		resource, ok := schema.(map[string]any)["$id"].(string)
+		if !ok {
+			t.Fatalf("%s has no $id", file)
+		}
+		if err := compiler.AddResource(resource, schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	compiled, err := compiler.Compile(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compiled
+}
+
+// write renders the test document and checks it against the schema, if any
+func write(t *testing.T, format Format, schema *jsonschema.Schema) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := Write(&out, testDocument(), format); err != nil {
+		t.Fatal(err)
+	}
+	if schema == nil {
+		return out.Bytes()
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		t.Errorf("%s output doesn't match the schema: %v\n%s", format, err, out.String())
+	}
+	return out.Bytes()
+}
+
+func TestCycloneDXMatchesSchema(t *testing.T) {
+	schema := compileSchema(t, "http://cyclonedx.org/schema/bom-1.5.schema.json")
+	out := write(t, FormatCycloneDX, schema)
+
+	var bom cdxBOM
+	if err := json.Unmarshal(out, &bom); err != nil {
+		t.Fatal(err)
+	}
+	dependsOn := make(map[string][]string)
+	for _, dep := range bom.Dependencies {
+		dependsOn[dep.Ref] = dep.DependsOn
+	}
+	want := []string{"pkg:brew/openssl%403@3.3.1", "pkg:brew/docker@4.32.0?type=cask&tap=homebrew%2Fcask"}
+	if got := dependsOn["pkg:brew/docker-tools@1.0?type=cask"]; !slices.Equal(got, want) {
+		t.Errorf("docker-tools depends on %v, want %v", got, want)
+	}
+}
+
+func TestSPDXMatchesSchema(t *testing.T) {
+	// The official schema is https://github.com/spdx/spdx-spec/blob/v2.3/schemas/spdx-schema.json
+	if _, err := os.Stat(filepath.Join("testdata", "spdx-schema.json")); err != nil {
+		t.Skip("testdata/spdx-schema.json (the SPDX 2.3 JSON schema) is missing")
+	}
+	write(t, FormatSPDX, compileSchema(t, "http://spdx.org/rdf/terms/2.3"))
+}
+
+func TestSPDXDependencies(t *testing.T) {
+	out := write(t, FormatSPDX, nil)
+
+	var document spdxDocument
+	if err := json.Unmarshal(out, &document); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rel := range document.Relationships {
+		if rel.SPDXElementID == "SPDXRef-formula-python-3.12" && rel.RelationshipType == "DEPENDS_ON" {
+			got = append(got, rel.RelatedSPDXElement)
+		}
+	}
+	want := []string{"SPDXRef-formula-openssl-3", "SPDXRef-formula-docker"}
+	if !slices.Equal(got, want) {
+		t.Errorf("python@3.12 depends on %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/license"
+)
+
+// SPDX 2.3 JSON, see https://spdx.github.io/spdx-spec/v2.3/
+
+const noAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Homepage         string            `json:"homepage,omitempty"`
+	Summary          string            `json:"summary,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment          string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func writeSPDX(w io.Writer, doc Document) error {
+	index := newPackageIndex(doc.Packages)
+
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.Tool + "-" + doc.Name,
+		DocumentNamespace: "https://github.com/lazar0169/brewst/sbom/" + doc.Name + "-" + newUUID(),
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Timestamp.Format(time.RFC3339),
+			Creators: []string{"Tool: " + doc.Tool},
+		},
+		Packages:      make([]spdxPackage, 0, len(doc.Packages)),
+		Relationships: []spdxRelationship{},
+	}
+
+	for _, pkg := range doc.Packages {
+		document.Packages = append(document.Packages, spdxPackageFor(pkg))
+		document.Relationships = append(document.Relationships, spdxRelationship{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: spdxID(pkg),
+		})
+		for _, dep := range index.dependencies(pkg) {
+			document.Relationships = append(document.Relationships, spdxRelationship{
+				SPDXElementID:      spdxID(pkg),
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: spdxID(dep),
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+func spdxPackageFor(pkg brew.Package) spdxPackage {
+	result := spdxPackage{
+		Name:             pkg.Name,
+		SPDXID:           spdxID(pkg),
+		VersionInfo:      pkg.Version,
+		DownloadLocation: noAssertion,
+		FilesAnalyzed:    false,
+		Summary:          pkg.Description,
+		LicenseConcluded: noAssertion,
+		LicenseDeclared:  noAssertion,
+		CopyrightText:    noAssertion,
+		ExternalRefs: []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl(pkg),
+		}},
+		Comment: "Homebrew " + string(pkg.Type),
+	}
+	if pkg.Tap != "" {
+		result.Comment += " from " + pkg.Tap
+	}
+
+	if isURL(pkg.SourceURL) {
+		result.DownloadLocation = pkg.SourceURL
+	}
+	if isURL(pkg.Homepage) {
+		result.Homepage = pkg.Homepage
+	}
+	if pkg.License != "" {
+		result.LicenseDeclared = spdxLicense(pkg.License)
+	}
+	if isHexDigest(pkg.Checksum) {
+		result.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: strings.ToLower(pkg.Checksum)}}
+	}
+
+	return result
+}
+
+var spdxIDInvalid = regexp.MustCompile(`[^A-Za-z0-9.\-]+`)
+
+// spdxID returns a document-unique identifier, formula and cask names can clash
+func spdxID(pkg brew.Package) string {
+	return "SPDXRef-" + string(pkg.Type) + "-" + spdxIDInvalid.ReplaceAllString(pkg.Name, "-")
+}
+
+var licenseIDPattern = regexp.MustCompile(`^[A-Za-z0-9.\-]+\+?$`)
+
+// spdxLicense returns the license as an SPDX expression, wrapping anything
+// that isn't one in a LicenseRef
+func spdxLicense(expression string) string {
+	expression = license.Normalize(expression)
+	if validExpression(expression) {
+		return expression
+	}
+	ref := strings.Trim(spdxIDInvalid.ReplaceAllString(expression, "-"), "-")
+	if ref == "" {
+		return noAssertion
+	}
+	return "LicenseRef-" + ref
+}
+
+// validExpression checks that identifiers and AND/OR/WITH operators alternate
+func validExpression(expression string) bool {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	expectID := true
+	for _, token := range strings.Fields(expression) {
+		isOperator := token == "AND" || token == "OR" || token == "WITH"
+		if expectID == isOperator || (!isOperator && !licenseIDPattern.MatchString(token)) {
+			return false
+		}
+		expectID = isOperator
+	}
+	return !expectID
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/hooks"
+)
+
+// JobStatus is the state of a queued operation
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// maxJobs is how many finished operations are kept for status queries
+const maxJobs = 100
+
+// Job is a mutating brew operation waiting in or run by the queue
+type Job struct {
+	mu sync.Mutex
+
+	ID         string
+	Operation  string
+	Args       []string
+	Status     JobStatus
+	Output     []string
+	Error      string
+	QueuedAt   time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	hook    hooks.Operation // What the hooks are told about the operation
+	changed chan struct{}   // Closed and replaced whenever the job changes
+}
+
+// jobJSON is the API representation of a job
+type jobJSON struct {
+	ID         string     `json:"id"`
+	Operation  string     `json:"operation"`
+	Args       []string   `json:"args"`
+	Status     JobStatus  `json:"status"`
+	Output     []string   `json:"output,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	QueuedAt   time.Time  `json:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func newJob(operation string, args []string) *Job {
+	return &Job{
+		ID:        newID(),
+		Operation: operation,
+		Args:      args,
+		Status:    JobQueued,
+		QueuedAt:  time.Now(),
+		changed:   make(chan struct{}),
+	}
+}
+
+// snapshot returns the job's output from line from, whether it has finished,
+// and a channel closed on the next change
+func (j *Job) snapshot(from int) (lines []string, done bool, changed <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if from < len(j.Output) {
+		lines = append(lines, j.Output[from:]...)
+	}
+	return lines, j.finished(), j.changed
+}
+
+func (j *Job) toJSON(withOutput bool) jobJSON {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	result := jobJSON{
+		ID:        j.ID,
+		Operation: j.Operation,
+		Args:      j.Args,
+		Status:    j.Status,
+		Error:     j.Error,
+		QueuedAt:  j.QueuedAt,
+	}
+	if withOutput {
+		result.Output = append([]string{}, j.Output...)
+	}
+	if !j.StartedAt.IsZero() {
+		started := j.StartedAt
+		result.StartedAt = &started
+	}
+	if !j.FinishedAt.IsZero() {
+		finished := j.FinishedAt
+		result.FinishedAt = &finished
+	}
+	return result
+}
+
+func (j *Job) finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// update changes the job under its lock and wakes up any watchers
+func (j *Job) update(fn func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn()
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// queue runs mutating operations one at a time, in submission order
+type queue struct {
+	client brew.Client
+	hooks  *hooks.Runner
+
+	mu    sync.Mutex
+	jobs  map[string]*Job
+	order []string // Job IDs, oldest first
+	work  chan *Job
+}
+
+func newQueue(client brew.Client) *queue {
+	return &queue{
+		client: client,
+		hooks:  hooks.NewRunner(hooks.Config{}),
+		jobs:   make(map[string]*Job),
+		work:   make(chan *Job, maxJobs),
+	}
+}
+
+// run processes jobs until ctx is cancelled
+func (q *queue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.work:
+			q.execute(ctx, job)
+		}
+	}
+}
+
+// submit queues a job, or reports false when the queue is full
+func (q *queue) submit(job *Job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.work <- job:
+	default:
+		return false
+	}
+
+	q.jobs[job.ID] = job
+	q.order = append(q.order, job.ID)
+	q.prune()
+	return true
+}
+
+// prune forgets the oldest finished jobs, caller holds the lock
+func (q *queue) prune() {
+	for len(q.order) > maxJobs {
+		oldest := q.jobs[q.order[0]]
+		oldest.mu.Lock()
+		finished := oldest.finished()
+		oldest.mu.Unlock()
+		if !finished {
+			return
+		}
+		delete(q.jobs, q.order[0])
+		q.order = q.order[1:]
+	}
+}
+
+func (q *queue) get(id string) *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs[id]
+}
+
+func (q *queue) list() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]*Job, 0, len(q.order))
+	for _, id := range q.order {
+		jobs = append(jobs, q.jobs[id])
+	}
+	return jobs
+}
+
+func (q *queue) execute(ctx context.Context, job *Job) {
+	job.update(func() {
+		job.Status = JobRunning
+		job.StartedAt = time.Now()
+	})
+
+	// The hooks run around the command as they do in the TUI, their output
+	// follows the command's
+	logs, err := q.hooks.Wrap(ctx, job.hook, func() error {
+		output, errs := q.client.Stream(ctx, job.Args)
+		for line := range output {
+			job.update(func() {
+				job.Output = append(job.Output, line)
+			})
+		}
+		return <-errs
+	})
+	if len(logs) > 0 {
+		job.update(func() {
+			job.Output = append(job.Output, logs...)
+		})
+	}
+
+	job.update(func() {
+		job.FinishedAt = time.Now()
+		if err != nil {
+			job.Status = JobFailed
+			job.Error = err.Error()
+		} else {
+			job.Status = JobSucceeded
+		}
+	})
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
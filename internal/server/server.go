@@ -0,0 +1,456 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/hooks"
+	"github.com/lazar0169/brewst/internal/policy"
+	"github.com/lazar0169/brewst/internal/state"
+)
+
+// Server exposes brew.Client over a local HTTP/JSON API. Reads run directly,
+// mutations go through a single queue and require the bearer token.
+type Server struct {
+	client brew.Client
+	token  string
+	config *state.Config // Policies and hooks, the same the TUI applies
+	queue  *queue
+	mux    *http.ServeMux
+}
+
+// New creates a server. Mutating requests must send "Authorization: Bearer <token>".
+func New(client brew.Client, token string) *Server {
+	s := &Server{
+		client: client,
+		token:  token,
+		config: &state.Config{},
+		queue:  newQueue(client),
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /v1/installed", s.handleInstalled)
+	s.mux.HandleFunc("GET /v1/outdated", s.handleOutdated)
+	s.mux.HandleFunc("GET /v1/info/{name}", s.handleInfo)
+	s.mux.HandleFunc("GET /v1/search", s.handleSearch)
+	s.mux.HandleFunc("POST /v1/install", s.authorized(s.handleInstall))
+	s.mux.HandleFunc("POST /v1/upgrade", s.authorized(s.handleUpgrade))
+	s.mux.HandleFunc("POST /v1/uninstall", s.authorized(s.handleUninstall))
+	s.mux.HandleFunc("GET /v1/operations", s.handleOperations)
+	s.mux.HandleFunc("GET /v1/operations/{id}", s.handleOperation)
+	s.mux.HandleFunc("GET /v1/operations/{id}/events", s.handleEvents)
+
+	return s
+}
+
+// Handle registers an extra handler, e.g. a metrics endpoint
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// SetConfig makes operations follow the config like the TUI does: installs
+// check the team install policy, upgrading everything follows the
+// auto-upgrade policy and the hooks run around each operation. The policy
+// file is read on every install so edits apply without a restart.
+func (s *Server) SetConfig(config *state.Config) {
+	s.config = config
+	s.queue.hooks = hooks.NewRunner(config.Hooks)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve runs the operation queue and serves requests until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go s.queue.run(ctx)
+
+	httpServer := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// packageJSON is the API representation of a package
+type packageJSON struct {
+	Name        string `json:"name"`
+	FullName    string `json:"full_name,omitempty"`
+	Version     string `json:"version"`
+	Description string `json:"desc,omitempty"`
+	Homepage    string `json:"homepage,omitempty"`
+	Tap         string `json:"tap,omitempty"`
+	Type        string `json:"type"`
+	Installed   bool   `json:"installed"`
+	Outdated    bool   `json:"outdated"`
+	Pinned      bool   `json:"pinned"`
+}
+
+// packageInfoJSON is the API representation of package details
+type packageInfoJSON struct {
+	packageJSON
+	Dependencies []string `json:"dependencies"`
+	BuildDeps    []string `json:"build_dependencies"`
+	Caveats      string   `json:"caveats,omitempty"`
+}
+
+func toPackageJSON(pkg brew.Package) packageJSON {
+	return packageJSON{
+		Name:        pkg.Name,
+		FullName:    pkg.FullName,
+		Version:     pkg.Version,
+		Description: pkg.Description,
+		Homepage:    pkg.Homepage,
+		Tap:         pkg.Tap,
+		Type:        string(pkg.Type),
+		Installed:   pkg.Installed,
+		Outdated:    pkg.Outdated,
+		Pinned:      pkg.Pinned,
+	}
+}
+
+func toPackagesJSON(packages []brew.Package) []packageJSON {
+	result := make([]packageJSON, len(packages))
+	for i, pkg := range packages {
+		result[i] = toPackageJSON(pkg)
+	}
+	return result
+}
+
+func (s *Server) handleInstalled(w http.ResponseWriter, r *http.Request) {
+	formulae, casks := true, true
+	switch r.URL.Query().Get("type") {
+	case "formula":
+		casks = false
+	case "cask":
+		formulae = false
+	case "":
+	default:
+		writeError(w, http.StatusBadRequest, "type must be formula or cask")
+		return
+	}
+
+	packages, err := s.client.ListInstalled(r.Context(), formulae, casks)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	// Mark outdated packages the same way the TUI does
+	outdated, err := s.client.Outdated(r.Context(), false)
+	if err == nil {
+		names := make(map[string]bool, len(outdated))
+		for _, pkg := range outdated {
+			names[string(pkg.Type)+"/"+pkg.Name] = true
+		}
+		for i := range packages {
+			packages[i].Outdated = names[string(packages[i].Type)+"/"+packages[i].Name]
+		}
+	}
+
+	writeJSON(w, http.StatusOK, toPackagesJSON(packages))
+}
+
+func (s *Server) handleOutdated(w http.ResponseWriter, r *http.Request) {
+	greedy, _ := strconv.ParseBool(r.URL.Query().Get("greedy"))
+	packages, err := s.client.Outdated(r.Context(), greedy)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if packages == nil {
+		packages = []brew.OutdatedPackage{}
+	}
+	writeJSON(w, http.StatusOK, packages)
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !validName(w, name) {
+		return
+	}
+	cask, _ := strconv.ParseBool(r.URL.Query().Get("cask"))
+	info, err := s.client.Info(r.Context(), name, cask)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, packageInfoJSON{
+		packageJSON:  toPackageJSON(info.Package),
+		Dependencies: info.Dependencies,
+		BuildDeps:    info.BuildDeps,
+		Caveats:      info.Caveats,
+	})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" || strings.HasPrefix(query, "-") {
+		writeError(w, http.StatusBadRequest, "missing or invalid query parameter q")
+		return
+	}
+	packages, err := s.client.Search(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toPackagesJSON(packages))
+}
+
+// packageRequest is the body of install and uninstall requests
+type packageRequest struct {
+	Name  string `json:"name"`
+	Cask  bool   `json:"cask"`
+	Force bool   `json:"force"`
+}
+
+// upgradeRequest is the body of upgrade requests, no packages upgrades everything
+type upgradeRequest struct {
+	Packages []string `json:"packages"`
+	Greedy   bool     `json:"greedy"`
+}
+
+func (s *Server) handleInstall(w http.ResponseWriter, r *http.Request) {
+	var req packageRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !validName(w, req.Name) {
+		return
+	}
+
+	p, err := policy.Load(s.config.PolicyPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	pkg := brew.Package{Name: req.Name, Type: brew.TypeFormula}
+	if req.Cask {
+		pkg.Type = brew.TypeCask
+	}
+	if d := p.CheckPackage(pkg); !d.Allowed {
+		writeError(w, http.StatusForbidden, "blocked by the team install policy: "+d.Reason)
+		return
+	}
+
+	job := newJob("install", brew.InstallArgs(req.Name, brew.InstallOptions{Cask: req.Cask, Force: req.Force}))
+	job.hook = hooks.Operation{Name: "install", Packages: []string{req.Name}, PackageType: string(pkg.Type)}
+	s.enqueue(w, job)
+}
+
+func (s *Server) handleUninstall(w http.ResponseWriter, r *http.Request) {
+	var req packageRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !validName(w, req.Name) {
+		return
+	}
+	pkgType := brew.TypeFormula
+	if req.Cask {
+		pkgType = brew.TypeCask
+	}
+	job := newJob("uninstall", brew.UninstallArgs(req.Name, brew.UninstallOptions{Cask: req.Cask, Force: req.Force}))
+	job.hook = hooks.Operation{Name: "uninstall", Packages: []string{req.Name}, PackageType: string(pkgType)}
+	s.enqueue(w, job)
+}
+
+func (s *Server) handleUpgrade(w http.ResponseWriter, r *http.Request) {
+	var req upgradeRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	for _, name := range req.Packages {
+		if !validName(w, name) {
+			return
+		}
+	}
+
+	outdated, err := s.client.Outdated(r.Context(), req.Greedy)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	packages := req.Packages
+	var held []string
+	if len(packages) == 0 && s.config.AutoUpgrade.Restricted() {
+		// Upgrading everything only applies the updates the auto-upgrade
+		// policy allows, like U in the TUI
+		for _, pkg := range outdated {
+			switch {
+			case pkg.Pinned:
+			case s.config.AutoUpgrade.Allows(pkg.Name, pkg.Update):
+				packages = append(packages, pkg.Name)
+			default:
+				held = append(held, fmt.Sprintf("%s %s → %s (%s)", pkg.Name, pkg.CurrentVersion, pkg.LatestVersion, pkg.Update))
+			}
+		}
+		if len(packages) == 0 {
+			writeError(w, http.StatusForbidden, "no updates are allowed by the auto-upgrade policy")
+			return
+		}
+	}
+
+	job := newJob("upgrade", brew.UpgradeArgs(packages, brew.UpgradeOptions{Greedy: req.Greedy}))
+	job.hook = hooks.Operation{Name: "upgrade", Packages: packages}
+	if len(packages) == 1 {
+		for _, pkg := range outdated {
+			if pkg.Name == packages[0] {
+				job.hook.PackageType = string(pkg.Type)
+				job.hook.FromVersion = pkg.CurrentVersion
+				job.hook.ToVersion = pkg.LatestVersion
+			}
+		}
+	}
+	if len(held) > 0 {
+		job.Output = append(job.Output, "Held back by the auto-upgrade policy: "+strings.Join(held, ", "))
+	}
+	s.enqueue(w, job)
+}
+
+func (s *Server) enqueue(w http.ResponseWriter, job *Job) {
+	if !s.queue.submit(job) {
+		writeError(w, http.StatusServiceUnavailable, "operation queue is full")
+		return
+	}
+	w.Header().Set("Location", "/v1/operations/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job.toJSON(false))
+}
+
+func (s *Server) handleOperations(w http.ResponseWriter, r *http.Request) {
+	jobs := s.queue.list()
+	result := make([]jobJSON, len(jobs))
+	for i, job := range jobs {
+		result[i] = job.toJSON(false)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleOperation(w http.ResponseWriter, r *http.Request) {
+	job := s.queue.get(r.PathValue("id"))
+	if job == nil {
+		writeError(w, http.StatusNotFound, "no such operation")
+		return
+	}
+	writeJSON(w, http.StatusOK, job.toJSON(true))
+}
+
+// handleEvents streams an operation's output as server-sent events: one
+// "output" event per line, then a "done" event with the final status
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	job := s.queue.get(r.PathValue("id"))
+	if job == nil {
+		writeError(w, http.StatusNotFound, "no such operation")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	sent := 0
+	for {
+		lines, done, changed := job.snapshot(sent)
+		for _, line := range lines {
+			writeEvent(w, "output", line)
+		}
+		sent += len(lines)
+
+		if done {
+			data, _ := json.Marshal(job.toJSON(false))
+			writeEvent(w, "done", string(data))
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// authorized rejects requests without the server's bearer token
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// validName rejects empty names and names brew would parse as flags
+func validName(w http.ResponseWriter, name string) bool {
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "missing package name")
+		return false
+	}
+	if strings.HasPrefix(name, "-") {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid package name %q", name))
+		return false
+	}
+	return true
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeEvent(w http.ResponseWriter, event, data string) {
+	fmt.Fprintf(w, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
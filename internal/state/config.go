@@ -2,8 +2,14 @@ package state
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/hooks"
+	"github.com/lazar0169/brewst/internal/license"
 )
 
 // Config represents user configuration
@@ -16,10 +22,102 @@ type Config struct {
 
 	// Behavior
 	AutoUpdateOnStartup bool `json:"auto_update_on_startup"`
-	CacheTTL            int  `json:"cache_ttl"` // seconds
+	UpdateInterval      int  `json:"update_interval"` // seconds between background update checks, 0 disables
+	CacheTTL            int  `json:"cache_ttl"`       // seconds
+	GreedyCasks         bool `json:"greedy_casks"`    // Include casks that update themselves
+
+	// Options packages were installed with, to reinstall and upgrade them the same way
+	InstallOptions map[string]brew.InstallOptions `json:"install_options,omitempty"`
+
+	// Vulnerability audit against a local OSV data dump
+	OSVPath       string   `json:"osv_path"`       // Directory of OSV JSON files, empty disables
+	OSVEcosystems []string `json:"osv_ecosystems"` // Match advisories from these ecosystems, vuln.DefaultEcosystems when empty
+
+	// Licenses that may not be installed
+	LicensePolicy license.Policy `json:"license_policy"`
+
+	// Team install policy file with allowed and denied packages and taps
+	PolicyPath string `json:"policy_path"`
+
+	// Team manifest of required packages, version bounds and taps (JSON or YAML)
+	ManifestPath string `json:"manifest_path"`
+
+	// Which updates upgrade-all applies
+	AutoUpgrade AutoUpgradePolicy `json:"auto_upgrade"`
 
 	// UI
-	DefaultView string `json:"default_view"`
+	DefaultView  string   `json:"default_view"`
+	SavedFilters []string `json:"saved_filters"`
+	SBOMFormat   string   `json:"sbom_format"` // cyclonedx or spdx, for exports from the TUI
+
+	// Commands run around install, upgrade and uninstall
+	Hooks hooks.Config `json:"hooks"`
+
+	// Record every brew command to a trace file, like the --debug flag
+	Debug     bool   `json:"debug"`
+	TracePath string `json:"trace_path"` // Defaults to ~/.config/brewst/trace.log
+}
+
+// AutoUpgradePolicy limits the updates upgrade-all applies. Each limit is the
+// biggest update allowed: "all" (the default), "minor", "patch", "revision"
+// or "none". Unrecognized limits allow nothing.
+type AutoUpgradePolicy struct {
+	Default  string            `json:"default"`
+	Packages map[string]string `json:"packages"` // Per-package limits by name
+}
+
+// Restricted reports whether the policy holds back any update
+func (p AutoUpgradePolicy) Restricted() bool {
+	if !allowsAll(p.Default) {
+		return true
+	}
+	for _, limit := range p.Packages {
+		if !allowsAll(limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether upgrade-all may apply an update of this kind to a package
+func (p AutoUpgradePolicy) Allows(name string, kind brew.UpdateKind) bool {
+	limit, ok := p.Packages[name]
+	if !ok {
+		limit = p.Default
+	}
+	switch strings.ToLower(limit) {
+	case "", "all", "major":
+		return true
+	case "minor":
+		return kind.AtMost(brew.UpdateMinor)
+	case "patch":
+		return kind.AtMost(brew.UpdatePatch)
+	case "revision":
+		return kind.AtMost(brew.UpdateRevision)
+	}
+	return false
+}
+
+func allowsAll(limit string) bool {
+	switch strings.ToLower(limit) {
+	case "", "all", "major":
+		return true
+	}
+	return false
+}
+
+// RememberInstallOptions records the options a package was installed with.
+// Options that change nothing are forgotten.
+func (c *Config) RememberInstallOptions(name string, opts brew.InstallOptions) {
+	if !opts.Customized() {
+		delete(c.InstallOptions, name)
+		return
+	}
+	if c.InstallOptions == nil {
+		c.InstallOptions = make(map[string]brew.InstallOptions)
+	}
+	opts.Force = false
+	c.InstallOptions[name] = opts
 }
 
 // DefaultConfig returns the default configuration
@@ -32,6 +130,7 @@ func DefaultConfig() *Config {
 		AutoUpdateOnStartup:    false,
 		CacheTTL:               300,
 		DefaultView:            "home",
+		SBOMFormat:             "cyclonedx",
 	}
 }
 
@@ -56,13 +155,13 @@ func LoadConfig() (*Config, error) {
 		return DefaultConfig(), err
 	}
 
-	// Parse config
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return DefaultConfig(), err
+	// Parse config over the defaults so newer options get sensible values
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return DefaultConfig(), fmt.Errorf("%s: %w", configPath, err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // Save saves the configuration to disk
@@ -98,6 +197,29 @@ func getConfigPath() (string, error) {
 	return filepath.Join(home, ".config", "brewst", "config.json"), nil
 }
 
+// ExpandHome expands a leading "~/" in paths from the config
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// GetTracePath returns the debug trace file, from the config or the default
+func (c *Config) GetTracePath() (string, error) {
+	if c.TracePath != "" {
+		return ExpandHome(c.TracePath), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "brewst", "trace.log"), nil
+}
+
 // LoadFavorites loads favorites from disk
 func LoadFavorites() ([]string, error) {
 	favPath, err := getFavoritesPath()
@@ -0,0 +1,218 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/sahilm/fuzzy"
+)
+
+// Filter is a parsed installed-list query.
+//
+// Supported terms (combined with AND, prefix with "-" to negate):
+//
+//	type:formula | type:cask
+//	outdated, pinned, fav
+//	tap:<user/repo>
+//	name~<substring>
+//	size>100M, size<=1G (>, >=, <, <=, =)
+//
+// Any other words are fuzzy-matched against the package name, each on its
+// own, and negated words exclude the names they match.
+type Filter struct {
+	Query string
+	terms []filterTerm
+	words []filterWord
+}
+
+type filterWord struct {
+	negate bool
+	text   string
+}
+
+type filterTerm struct {
+	negate bool
+	match  func(pkg brew.Package, favorite bool) bool
+}
+
+// ParseFilter parses a filter query
+func ParseFilter(query string) (*Filter, error) {
+	f := &Filter{Query: strings.TrimSpace(query)}
+
+	for _, word := range strings.Fields(f.Query) {
+		negate := false
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			negate = true
+			word = word[1:]
+		}
+
+		match, err := parseFilterTerm(word)
+		if err != nil {
+			return nil, err
+		}
+		if match == nil {
+			// Not a recognised term, use it for fuzzy matching
+			f.words = append(f.words, filterWord{negate: negate, text: word})
+			continue
+		}
+
+		f.terms = append(f.terms, filterTerm{negate: negate, match: match})
+	}
+	return f, nil
+}
+
+// parseFilterTerm returns the predicate for a single query word, or nil if
+// the word is free text
+func parseFilterTerm(word string) (func(brew.Package, bool) bool, error) {
+	lower := strings.ToLower(word)
+
+	switch lower {
+	case "outdated":
+		return func(pkg brew.Package, _ bool) bool { return pkg.Outdated }, nil
+	case "pinned":
+		return func(pkg brew.Package, _ bool) bool { return pkg.Pinned }, nil
+	case "fav", "favorite", "favorites":
+		return func(_ brew.Package, favorite bool) bool { return favorite }, nil
+	}
+
+	if expr, ok := strings.CutPrefix(lower, "size"); ok && strings.ContainsAny(expr[:min(len(expr), 1)], "<>=") {
+		return parseSizeTerm(expr)
+	}
+
+	if key, value, ok := strings.Cut(lower, "~"); ok && key == "name" {
+		return func(pkg brew.Package, _ bool) bool {
+			return strings.Contains(strings.ToLower(pkg.Name), value)
+		}, nil
+	}
+
+	key, value, ok := strings.Cut(lower, ":")
+	if !ok {
+		return nil, nil
+	}
+
+	switch key {
+	case "type":
+		switch value {
+		case "formula", "formulae":
+			return func(pkg brew.Package, _ bool) bool { return pkg.Type == brew.TypeFormula }, nil
+		case "cask", "casks":
+			return func(pkg brew.Package, _ bool) bool { return pkg.Type == brew.TypeCask }, nil
+		}
+		return nil, fmt.Errorf("unknown type %q (use formula or cask)", value)
+	case "tap":
+		return func(pkg brew.Package, _ bool) bool { return strings.ToLower(pkg.Tap) == value }, nil
+	case "name":
+		return func(pkg brew.Package, _ bool) bool { return strings.ToLower(pkg.Name) == value }, nil
+	}
+
+	return nil, fmt.Errorf("unknown filter %q", key)
+}
+
+// parseSizeTerm parses the comparison part of a size term such as ">100M"
+func parseSizeTerm(expr string) (func(brew.Package, bool) bool, error) {
+	var op string
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(expr, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid size filter %q (e.g. size>100M)", "size"+expr)
+	}
+
+	limit, err := brew.ParseSize(expr[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pkg brew.Package, _ bool) bool {
+		switch op {
+		case ">=":
+			return pkg.Size >= limit
+		case "<=":
+			return pkg.Size <= limit
+		case ">":
+			return pkg.Size > limit
+		case "<":
+			return pkg.Size < limit
+		default:
+			return pkg.Size == limit
+		}
+	}, nil
+}
+
+// IsEmpty returns whether the filter matches everything
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.terms) == 0 && len(f.words) == 0)
+}
+
+// Apply returns the packages matching the filter
+func (f *Filter) Apply(packages []brew.Package, favorites map[string]bool) []brew.Package {
+	if f.IsEmpty() {
+		return packages
+	}
+
+	var matched []brew.Package
+	for _, pkg := range packages {
+		if f.matches(pkg, favorites[pkg.Name]) {
+			matched = append(matched, pkg)
+		}
+	}
+
+	if len(f.words) == 0 {
+		return matched
+	}
+
+	// Fuzzy match the free text words, best matches first
+	names := make([]string, len(matched))
+	for i, pkg := range matched {
+		names[i] = pkg.Name
+	}
+
+	scores := make([]int, len(matched))
+	hits := make([]int, len(matched))
+	excluded := make([]bool, len(matched))
+	positive := 0
+	for _, word := range f.words {
+		if !word.negate {
+			positive++
+		}
+		for _, result := range fuzzy.Find(word.text, names) {
+			if word.negate {
+				excluded[result.Index] = true
+				continue
+			}
+			scores[result.Index] += result.Score
+			hits[result.Index]++
+		}
+	}
+
+	var indexes []int
+	for i := range matched {
+		if hits[i] == positive && !excluded[i] {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+
+	filtered := make([]brew.Package, 0, len(indexes))
+	for _, i := range indexes {
+		filtered = append(filtered, matched[i])
+	}
+
+	return filtered
+}
+
+func (f *Filter) matches(pkg brew.Package, favorite bool) bool {
+	for _, term := range f.terms {
+		if term.match(pkg, favorite) == term.negate {
+			return false
+		}
+	}
+	return true
+}
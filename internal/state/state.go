@@ -1,9 +1,11 @@
 package state
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/vuln"
 )
 
 // State represents the global application state
@@ -31,13 +33,30 @@ type State struct {
 	ShowCasks    bool
 	OnlyOutdated bool
 	OnlyPinned   bool
+	Filter       *Filter
+	SortBySize   bool
 
 	// User preferences
 	Favorites []string
 
+	// Search queries of this session, newest first
+	RecentSearches []string
+
+	// Disk usage per Cellar rack and Caskroom entry
+	DiskUsage        []brew.DiskUsage
+	TotalDiskUsage   int64
+	ReclaimableUsage int64
+
 	// Statistics
 	TotalInstalled int
 	TotalOutdated  int
+
+	// Known vulnerabilities affecting installed packages
+	Vulnerabilities []vuln.Finding
+
+	// Updates seen when the app started, by name, and how many appeared since
+	initialUpdates map[string]string
+	NewUpdates     int
 }
 
 // NewState creates a new application state
@@ -55,6 +74,53 @@ func (s *State) SetInstalled(packages []brew.Package) {
 	defer s.mu.Unlock()
 	s.InstalledPackages = packages
 	s.TotalInstalled = len(packages)
+	s.applyDiskUsage()
+	s.applyOutdated()
+}
+
+// SetDiskUsage sets the disk usage and updates installed package sizes
+func (s *State) SetDiskUsage(usage []brew.DiskUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.DiskUsage = usage
+	s.TotalDiskUsage = 0
+	s.ReclaimableUsage = 0
+	for _, u := range usage {
+		s.TotalDiskUsage += u.Size
+		s.ReclaimableUsage += u.Reclaimable
+	}
+	s.applyDiskUsage()
+}
+
+// GetDiskUsage returns total and reclaimable disk usage in bytes
+func (s *State) GetDiskUsage() (total int64, reclaimable int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.TotalDiskUsage, s.ReclaimableUsage
+}
+
+// applyDiskUsage copies sizes onto installed packages, caller holds the lock
+func (s *State) applyDiskUsage() {
+	sizes := make(map[brew.PackageType]map[string]int64)
+	for _, u := range s.DiskUsage {
+		if sizes[u.Type] == nil {
+			sizes[u.Type] = make(map[string]int64)
+		}
+		sizes[u.Type][u.Name] = u.Size
+	}
+
+	for i := range s.InstalledPackages {
+		pkg := &s.InstalledPackages[i]
+		pkg.Size = sizes[pkg.Type][pkg.Name]
+	}
+}
+
+// ToggleSortBySize toggles sorting the installed list by size
+func (s *State) ToggleSortBySize() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SortBySize = !s.SortBySize
+	return s.SortBySize
 }
 
 // SetOutdated sets the outdated packages
@@ -63,6 +129,73 @@ func (s *State) SetOutdated(packages []brew.OutdatedPackage) {
 	defer s.mu.Unlock()
 	s.OutdatedPackages = packages
 	s.TotalOutdated = len(packages)
+	s.applyOutdated()
+	s.countNewUpdates()
+}
+
+// ClearOutdated empties the outdated packages after they failed to load,
+// without taking the empty list as the baseline for new updates
+func (s *State) ClearOutdated() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.OutdatedPackages = []brew.OutdatedPackage{}
+	s.TotalOutdated = 0
+	s.applyOutdated()
+}
+
+// countNewUpdates counts updates that weren't available when the app started,
+// caller holds the lock
+func (s *State) countNewUpdates() {
+	if s.initialUpdates == nil {
+		s.initialUpdates = make(map[string]string, len(s.OutdatedPackages))
+		for _, pkg := range s.OutdatedPackages {
+			s.initialUpdates[pkg.Name] = pkg.LatestVersion
+		}
+		return
+	}
+
+	s.NewUpdates = 0
+	for _, pkg := range s.OutdatedPackages {
+		if latest, ok := s.initialUpdates[pkg.Name]; !ok || latest != pkg.LatestVersion {
+			s.NewUpdates++
+		}
+	}
+}
+
+// GetNewUpdates returns how many updates appeared since the app started
+func (s *State) GetNewUpdates() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.NewUpdates
+}
+
+// applyOutdated marks installed packages that have updates, caller holds the lock
+func (s *State) applyOutdated() {
+	outdated := make(map[brew.PackageType]map[string]bool)
+	for _, pkg := range s.OutdatedPackages {
+		if outdated[pkg.Type] == nil {
+			outdated[pkg.Type] = make(map[string]bool)
+		}
+		outdated[pkg.Type][pkg.Name] = true
+	}
+
+	for i := range s.InstalledPackages {
+		pkg := &s.InstalledPackages[i]
+		pkg.Outdated = outdated[pkg.Type][pkg.Name]
+	}
+}
+
+// GetOutdatedPackage returns the update information for an installed package
+func (s *State) GetOutdatedPackage(name string) (brew.OutdatedPackage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pkg := range s.OutdatedPackages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return brew.OutdatedPackage{}, false
 }
 
 // SetSearchResults sets the search results
@@ -72,6 +205,30 @@ func (s *State) SetSearchResults(packages []brew.Package) {
 	s.SearchResults = packages
 }
 
+// maxRecentSearches bounds the remembered search queries
+const maxRecentSearches = 10
+
+// AddRecentSearch remembers a search query, moving it to the front if it was
+// searched before
+func (s *State) AddRecentSearch(query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recent := []string{query}
+	for _, q := range s.RecentSearches {
+		if q != query && len(recent) < maxRecentSearches {
+			recent = append(recent, q)
+		}
+	}
+	s.RecentSearches = recent
+}
+
+// GetRecentSearches returns the remembered search queries, newest first
+func (s *State) GetRecentSearches() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.RecentSearches...)
+}
+
 // SetSelectedPackage sets the currently selected package
 func (s *State) SetSelectedPackage(pkg *brew.Package) {
 	s.mu.Lock()
@@ -141,9 +298,37 @@ func (s *State) GetFilteredPackages() []brew.Package {
 		filtered = append(filtered, pkg)
 	}
 
+	if !s.Filter.IsEmpty() {
+		favorites := make(map[string]bool, len(s.Favorites))
+		for _, fav := range s.Favorites {
+			favorites[fav] = true
+		}
+		filtered = s.Filter.Apply(filtered, favorites)
+	}
+
+	if s.SortBySize {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].Size > filtered[j].Size
+		})
+	}
+
 	return filtered
 }
 
+// SetFilter sets the installed list filter, nil clears it
+func (s *State) SetFilter(filter *Filter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Filter = filter
+}
+
+// GetFilter returns the active installed list filter
+func (s *State) GetFilter() *Filter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Filter
+}
+
 // IsFavorite checks if a package is in favorites
 func (s *State) IsFavorite(name string) bool {
 	s.mu.RLock()
@@ -174,6 +359,66 @@ func (s *State) ToggleFavorite(name string) {
 	s.Favorites = append(s.Favorites, name)
 }
 
+// GetInstalledPackage returns the installed package with the given name
+func (s *State) GetInstalledPackage(name string) (brew.Package, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pkg := range s.InstalledPackages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return brew.Package{}, false
+}
+
+// SetVulnerabilities sets the vulnerabilities found in installed packages
+func (s *State) SetVulnerabilities(findings []vuln.Finding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Vulnerabilities = findings
+}
+
+// GetVulnerabilities returns the vulnerabilities found in installed packages
+func (s *State) GetVulnerabilities() []vuln.Finding {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Vulnerabilities
+}
+
+// GetVulnerabilitySeverity returns the most severe vulnerability affecting a
+// package and how many there are
+func (s *State) GetVulnerabilitySeverity(name string) (vuln.Severity, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	severity, count := vuln.SeverityUnknown, 0
+	for _, finding := range s.Vulnerabilities {
+		if finding.Package.Name != name {
+			continue
+		}
+		count++
+		if finding.Advisory.Severity > severity {
+			severity = finding.Advisory.Severity
+		}
+	}
+	return severity, count
+}
+
+// GetInstalledPackages returns a copy of all installed packages
+func (s *State) GetInstalledPackages() []brew.Package {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]brew.Package(nil), s.InstalledPackages...)
+}
+
+// GetOutdatedPackages returns a copy of all outdated packages
+func (s *State) GetOutdatedPackages() []brew.OutdatedPackage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]brew.OutdatedPackage(nil), s.OutdatedPackages...)
+}
+
 // GetInstalledCount returns the number of installed packages
 func (s *State) GetInstalledCount() int {
 	s.mu.RLock()
@@ -187,3 +432,42 @@ func (s *State) GetOutdatedCount() int {
 	defer s.mu.RUnlock()
 	return s.TotalOutdated
 }
+
+// Summary holds package health counts derived from the state
+type Summary struct {
+	Installed   map[brew.PackageType]int
+	Outdated    map[brew.PackageType]int
+	Pinned      int
+	Deprecated  int
+	DiskUsage   map[brew.PackageType]int64 // Bytes in the Cellar and Caskroom
+	Reclaimable int64
+}
+
+// GetSummary counts installed, outdated, pinned and deprecated packages
+func (s *State) GetSummary() Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary := Summary{
+		Installed:   map[brew.PackageType]int{brew.TypeFormula: 0, brew.TypeCask: 0},
+		Outdated:    map[brew.PackageType]int{brew.TypeFormula: 0, brew.TypeCask: 0},
+		DiskUsage:   map[brew.PackageType]int64{brew.TypeFormula: 0, brew.TypeCask: 0},
+		Reclaimable: s.ReclaimableUsage,
+	}
+	for _, pkg := range s.InstalledPackages {
+		summary.Installed[pkg.Type]++
+		if pkg.Pinned {
+			summary.Pinned++
+		}
+		if pkg.Deprecated {
+			summary.Deprecated++
+		}
+	}
+	for _, pkg := range s.OutdatedPackages {
+		summary.Outdated[pkg.Type]++
+	}
+	for _, u := range s.DiskUsage {
+		summary.DiskUsage[u.Type] += u.Size
+	}
+	return summary
+}
@@ -0,0 +1,226 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// ChecklistItem represents an item that can be included or excluded
+type ChecklistItem struct {
+	Label   string
+	Detail  string
+	Checked bool
+}
+
+// ChecklistDialog is a scrollable confirmation dialog with checkable items
+type ChecklistDialog struct {
+	title   string
+	message string
+	items   []ChecklistItem
+	cursor  int
+	scroll  int
+	height  int // Visible item rows
+	visible bool
+}
+
+// ChecklistDialogMsg is sent when a checklist dialog is closed
+type ChecklistDialogMsg struct {
+	Confirmed bool
+	Items     []ChecklistItem // Checked items only
+}
+
+// NewChecklistDialog creates a new checklist dialog
+func NewChecklistDialog(title string) *ChecklistDialog {
+	return &ChecklistDialog{
+		title:  title,
+		height: 10,
+	}
+}
+
+// Show shows the dialog with the given items, all checked
+func (d *ChecklistDialog) Show(message string, items []ChecklistItem) {
+	d.message = message
+	d.items = items
+	for i := range d.items {
+		d.items[i].Checked = true
+	}
+	d.cursor = 0
+	d.scroll = 0
+	d.visible = true
+}
+
+// Hide hides the dialog
+func (d *ChecklistDialog) Hide() {
+	d.visible = false
+}
+
+// IsVisible returns whether the dialog is visible
+func (d *ChecklistDialog) IsVisible() bool {
+	return d.visible
+}
+
+// SetTitle sets the dialog title
+func (d *ChecklistDialog) SetTitle(title string) {
+	d.title = title
+}
+
+// SetHeight sets the number of visible item rows
+func (d *ChecklistDialog) SetHeight(height int) {
+	if height < 3 {
+		height = 3
+	}
+	d.height = height
+}
+
+// Checked returns the checked items
+func (d *ChecklistDialog) Checked() []ChecklistItem {
+	var checked []ChecklistItem
+	for _, item := range d.items {
+		if item.Checked {
+			checked = append(checked, item)
+		}
+	}
+	return checked
+}
+
+// Update handles dialog input
+func (d *ChecklistDialog) Update(msg tea.Msg) (*ChecklistDialog, tea.Cmd) {
+	if !d.visible {
+		return d, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if d.cursor > 0 {
+				d.cursor--
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if d.cursor < len(d.items)-1 {
+				d.cursor++
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys(" ", "space", "x"))):
+			if d.cursor < len(d.items) {
+				d.items[d.cursor].Checked = !d.items[d.cursor].Checked
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+			// Toggle all: uncheck everything if all are checked
+			all := len(d.Checked()) == len(d.items)
+			for i := range d.items {
+				d.items[i].Checked = !all
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			d.visible = false
+			checked := d.Checked()
+			return d, func() tea.Msg {
+				return ChecklistDialogMsg{Confirmed: len(checked) > 0, Items: checked}
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			d.visible = false
+			return d, func() tea.Msg {
+				return ChecklistDialogMsg{Confirmed: false}
+			}
+		}
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			if d.cursor > 0 {
+				d.cursor--
+			}
+		case tea.MouseButtonWheelDown:
+			if d.cursor < len(d.items)-1 {
+				d.cursor++
+			}
+		}
+	}
+
+	// Keep the cursor visible
+	if d.cursor < d.scroll {
+		d.scroll = d.cursor
+	}
+	if d.cursor >= d.scroll+d.height {
+		d.scroll = d.cursor - d.height + 1
+	}
+
+	return d, nil
+}
+
+// View renders the dialog
+func (d *ChecklistDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	title := styles.DialogTitleStyle.Render(d.title)
+	message := styles.ValueStyle.Render(d.message)
+
+	var rows []string
+	end := d.scroll + d.height
+	if end > len(d.items) {
+		end = len(d.items)
+	}
+	for i := d.scroll; i < end; i++ {
+		item := d.items[i]
+
+		check := "[ ]"
+		if item.Checked {
+			check = "[x]"
+		}
+
+		label := item.Label
+		if len(label) > 40 {
+			label = "..." + label[len(label)-37:]
+		}
+		row := fmt.Sprintf("%s %-40s %s", check, label, styles.DimStyle.Render(item.Detail))
+		if i == d.cursor {
+			row = styles.SelectedStyle.Render("▶ " + row)
+		} else {
+			row = "  " + row
+		}
+		rows = append(rows, row)
+	}
+
+	if d.scroll > 0 {
+		rows = append([]string{styles.DimStyle.Render(fmt.Sprintf("  ↑ %d above", d.scroll))}, rows...)
+	}
+	if end < len(d.items) {
+		rows = append(rows, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(d.items)-end)))
+	}
+
+	help := styles.DimStyle.Render(fmt.Sprintf("%d of %d selected • Space: Toggle • a: All • Enter: Confirm • Esc: Cancel",
+		len(d.Checked()), len(d.items)))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		message,
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		help,
+	)
+
+	return styles.DialogBoxStyle.Width(80).Render(content)
+}
+
+// Overlay renders the dialog as an overlay on top of content
+func (d *ChecklistDialog) Overlay(content string, width, height int) string {
+	if !d.visible {
+		return content
+	}
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		d.View(),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(styles.Muted),
+	)
+}
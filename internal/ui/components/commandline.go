@@ -0,0 +1,254 @@
+package components
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// maxCommandHistory bounds the command line history
+const maxCommandHistory = 50
+
+// CommandLineMsg is sent when a command is entered
+type CommandLineMsg struct {
+	Line string
+}
+
+// CommandLine is a vim-style ":" prompt on the bottom line of the screen. It
+// keeps a history of entered commands and completes words with Tab.
+type CommandLine struct {
+	input   textinput.Model
+	width   int
+	visible bool
+	message string // Result of the last command, shown until the next key
+	isError bool
+
+	history []string // Entered commands, oldest first
+	recall  int      // Position in history while browsing it, len(history) for the new line
+	draft   string   // Line being typed before browsing the history
+
+	commands []string // Completions for the first word
+	words    []string // Completions for the other words
+
+	// Tab cycles through the completions of the word before the cursor
+	completions []string
+	completion  int
+	base        string // Line up to the completed word
+	rest        string // Line after the cursor
+}
+
+// NewCommandLine creates a new command line
+func NewCommandLine() *CommandLine {
+	input := textinput.New()
+	input.Prompt = ":"
+	input.CharLimit = 256
+
+	return &CommandLine{input: input}
+}
+
+// Show opens the command line with an empty line
+func (c *CommandLine) Show() tea.Cmd {
+	c.input.SetValue("")
+	c.recall = len(c.history)
+	c.draft = ""
+	c.completions = nil
+	c.message = ""
+	c.visible = true
+	return c.input.Focus()
+}
+
+// Hide hides the command line
+func (c *CommandLine) Hide() {
+	c.visible = false
+	c.input.Blur()
+}
+
+// IsVisible returns whether the command line is open
+func (c *CommandLine) IsVisible() bool {
+	return c.visible
+}
+
+// SetWidth sets the width of the command line
+func (c *CommandLine) SetWidth(width int) {
+	c.width = width
+}
+
+// SetCompletions sets the words Tab completes: commands for the first word
+// and words, like package names, for the arguments
+func (c *CommandLine) SetCompletions(commands, words []string) {
+	c.commands = commands
+	c.words = words
+}
+
+// SetMessage shows the result of a command in place of the closed prompt
+func (c *CommandLine) SetMessage(message string, isError bool) {
+	c.message = message
+	c.isError = isError
+}
+
+// ClearMessage removes the result of the last command
+func (c *CommandLine) ClearMessage() {
+	c.message = ""
+}
+
+// HasMessage reports whether a result is shown
+func (c *CommandLine) HasMessage() bool {
+	return !c.visible && c.message != ""
+}
+
+// Update handles command line input
+func (c *CommandLine) Update(msg tea.Msg) (*CommandLine, tea.Cmd) {
+	if !c.visible {
+		return c, nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return c, nil
+	}
+
+	if keyMsg.String() != "tab" {
+		c.completions = nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "ctrl+c":
+		c.Hide()
+		return c, nil
+
+	case "enter":
+		line := strings.TrimSpace(c.input.Value())
+		c.Hide()
+		if line == "" {
+			return c, nil
+		}
+		c.remember(line)
+		return c, func() tea.Msg { return CommandLineMsg{Line: line} }
+
+	case "backspace":
+		// Backspace on an empty line closes it, as in vim
+		if c.input.Value() == "" {
+			c.Hide()
+			return c, nil
+		}
+
+	case "up", "ctrl+p":
+		c.browse(-1)
+		return c, nil
+
+	case "down", "ctrl+n":
+		c.browse(1)
+		return c, nil
+
+	case "tab":
+		c.complete()
+		return c, nil
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return c, cmd
+}
+
+// remember adds a line to the history, moving a repeated line to the end
+func (c *CommandLine) remember(line string) {
+	for i, previous := range c.history {
+		if previous == line {
+			c.history = append(c.history[:i], c.history[i+1:]...)
+			break
+		}
+	}
+	c.history = append(c.history, line)
+	if len(c.history) > maxCommandHistory {
+		c.history = c.history[len(c.history)-maxCommandHistory:]
+	}
+}
+
+// browse moves through the history, older (negative) or newer (positive)
+func (c *CommandLine) browse(delta int) {
+	recall := min(max(c.recall+delta, 0), len(c.history))
+	if recall == c.recall {
+		return
+	}
+	if c.recall == len(c.history) {
+		c.draft = c.input.Value()
+	}
+	c.recall = recall
+	if recall == len(c.history) {
+		c.input.SetValue(c.draft)
+	} else {
+		c.input.SetValue(c.history[recall])
+	}
+	c.input.CursorEnd()
+}
+
+// complete replaces the word before the cursor with its next completion
+func (c *CommandLine) complete() {
+	if c.completions == nil {
+		value := []rune(c.input.Value())
+		line := string(value[:c.input.Position()])
+		start := strings.LastIndex(line, " ") + 1
+		prefix := line[start:]
+
+		candidates := c.words
+		if strings.TrimSpace(line[:start]) == "" {
+			candidates = c.commands
+		}
+		seen := make(map[string]bool)
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, prefix) && !seen[candidate] {
+				seen[candidate] = true
+				c.completions = append(c.completions, candidate)
+			}
+		}
+		if len(c.completions) == 0 {
+			return
+		}
+		sort.Strings(c.completions)
+		c.base = line[:start]
+		c.rest = string(value[c.input.Position():])
+		c.completion = -1
+	}
+
+	c.completion = (c.completion + 1) % len(c.completions)
+	word := c.completions[c.completion]
+	if len(c.completions) == 1 {
+		// Nothing to cycle through, move on to the next word
+		word += " "
+		c.completions = nil
+	}
+	c.input.SetValue(c.base + word + c.rest)
+	c.input.SetCursor(len([]rune(c.base + word)))
+}
+
+// View renders the prompt, or the result of the last command
+func (c *CommandLine) View() string {
+	if !c.visible {
+		style := styles.DimStyle
+		if c.isError {
+			style = styles.ErrorStyle
+		}
+		return lipgloss.NewStyle().MaxWidth(c.width).Render(style.Render(c.message))
+	}
+
+	c.input.Width = max(c.width-lipgloss.Width(c.input.Prompt)-1, 1)
+	view := c.input.View()
+	if len(c.completions) > 1 {
+		hint := styles.DimStyle.Render("  " + strings.Join(c.completions, " "))
+		view = lipgloss.NewStyle().MaxWidth(c.width).Render(c.input.Prompt + c.input.Value() + hint)
+	}
+	return view
+}
+
+// Overlay renders the command line over the last line of content
+func (c *CommandLine) Overlay(content string) string {
+	if !c.visible && c.message == "" {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	lines[len(lines)-1] = c.View()
+	return strings.Join(lines, "\n")
+}
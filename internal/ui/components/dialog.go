@@ -24,6 +24,7 @@ type Dialog struct {
 	selectedBtn int
 	buttons     []string
 	visible     bool
+	buttonZones []Zone // Where the buttons were drawn by the last Overlay
 	onConfirm   func()
 	onCancel    func()
 }
@@ -61,6 +62,7 @@ func NewConfirmDialog(title, message string) *Dialog {
 func (d *Dialog) Show() {
 	d.visible = true
 	d.selectedBtn = 0
+	d.buttonZones = nil
 }
 
 // Hide hides the dialog
@@ -98,49 +100,66 @@ func (d *Dialog) Update(msg tea.Msg) (*Dialog, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
 			d.selectedBtn = (d.selectedBtn + 1) % len(d.buttons)
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
-			d.visible = false
-			if d.dialogType == DialogConfirm {
-				confirmed := d.selectedBtn == 0
-				return d, func() tea.Msg {
-					return DialogMsg{Confirmed: confirmed}
-				}
-			}
-			return d, func() tea.Msg {
-				return DialogMsg{Confirmed: true}
-			}
+			return d, d.choose()
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
 			d.visible = false
 			return d, func() tea.Msg {
 				return DialogMsg{Confirmed: false}
 			}
 		}
+
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+			break
+		}
+		for i, zone := range d.buttonZones {
+			if zone.Contains(msg.X, msg.Y) {
+				d.selectedBtn = i
+				return d, d.choose()
+			}
+		}
 	}
 
 	return d, nil
 }
 
+// choose closes the dialog with the selected button
+func (d *Dialog) choose() tea.Cmd {
+	d.visible = false
+	if d.dialogType == DialogConfirm {
+		confirmed := d.selectedBtn == 0
+		return func() tea.Msg {
+			return DialogMsg{Confirmed: confirmed}
+		}
+	}
+	return func() tea.Msg {
+		return DialogMsg{Confirmed: true}
+	}
+}
+
 // View renders the dialog
 func (d *Dialog) View() string {
 	if !d.visible {
 		return ""
 	}
 
+	// Center the dialog
+	return lipgloss.Place(
+		80, 10,
+		lipgloss.Center, lipgloss.Center,
+		d.box(),
+	)
+}
+
+// box renders the dialog box itself
+func (d *Dialog) box() string {
 	// Title
 	title := styles.DialogTitleStyle.Render(d.title)
 
 	// Message
 	message := styles.ValueStyle.Render(d.message)
 
-	// Buttons
-	var buttons []string
-	for i, btnText := range d.buttons {
-		if i == d.selectedBtn {
-			buttons = append(buttons, styles.DialogButtonActiveStyle.Render(btnText))
-		} else {
-			buttons = append(buttons, styles.DialogButtonStyle.Render(btnText))
-		}
-	}
-	buttonsRow := lipgloss.JoinHorizontal(lipgloss.Left, buttons...)
+	buttonsRow := lipgloss.JoinHorizontal(lipgloss.Left, d.renderButtons()...)
 
 	// Combine all elements
 	content := lipgloss.JoinVertical(
@@ -153,14 +172,20 @@ func (d *Dialog) View() string {
 	)
 
 	// Apply dialog box style
-	dialog := styles.DialogBoxStyle.Render(content)
+	return styles.DialogBoxStyle.Render(content)
+}
 
-	// Center the dialog
-	return lipgloss.Place(
-		80, 10,
-		lipgloss.Center, lipgloss.Center,
-		dialog,
-	)
+// renderButtons renders each button, with the selected one highlighted
+func (d *Dialog) renderButtons() []string {
+	var buttons []string
+	for i, btnText := range d.buttons {
+		if i == d.selectedBtn {
+			buttons = append(buttons, styles.DialogButtonActiveStyle.Render(btnText))
+		} else {
+			buttons = append(buttons, styles.DialogButtonStyle.Render(btnText))
+		}
+	}
+	return buttons
 }
 
 // Overlay renders the dialog as an overlay on top of content
@@ -170,6 +195,7 @@ func (d *Dialog) Overlay(content string, width, height int) string {
 	}
 
 	dialog := d.View()
+	d.placeButtons(width, height)
 
 	// Place dialog in center of screen
 	overlay := lipgloss.Place(
@@ -182,3 +208,22 @@ func (d *Dialog) Overlay(content string, width, height int) string {
 
 	return overlay
 }
+
+// placeButtons records where Overlay draws the buttons. The box is centered
+// in View's 80x10 area, which is centered on the screen, and the buttons are
+// the last line inside the box's border and padding.
+func (d *Dialog) placeButtons(width, height int) {
+	boxWidth, boxHeight := lipgloss.Size(d.box())
+	viewWidth, viewHeight := max(80, boxWidth), max(10, boxHeight)
+	x := centerOffset(width, viewWidth) + centerOffset(viewWidth, boxWidth) +
+		styles.DialogBoxStyle.GetBorderLeftSize() + styles.DialogBoxStyle.GetPaddingLeft()
+	y := centerOffset(height, viewHeight) + centerOffset(viewHeight, boxHeight) + boxHeight - 1 -
+		styles.DialogBoxStyle.GetBorderBottomSize() - styles.DialogBoxStyle.GetPaddingBottom()
+
+	d.buttonZones = d.buttonZones[:0]
+	for _, button := range d.renderButtons() {
+		buttonWidth := lipgloss.Width(button)
+		d.buttonZones = append(d.buttonZones, Zone{X: x, Y: y, Width: buttonWidth - styles.DialogButtonStyle.GetMarginRight(), Height: 1})
+		x += buttonWidth
+	}
+}
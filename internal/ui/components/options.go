@@ -0,0 +1,215 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// OptionsChoice is one variant of the operation an options dialog confirms,
+// like installing a name as a formula or as a cask, with its own options
+type OptionsChoice struct {
+	Label   string
+	Message string
+	Options []ChecklistItem
+}
+
+// optionsDialogWidth is the width of the dialog box, padding included
+const optionsDialogWidth = 80
+
+// OptionsDialog confirms an operation with options to check. When there is
+// more than one choice, ←/→ switch between them.
+type OptionsDialog struct {
+	title   string
+	choices []OptionsChoice
+	choice  int
+	cursor  int
+	scroll  int
+	height  int // Visible option rows
+	visible bool
+}
+
+// OptionsDialogMsg is sent when an options dialog is closed
+type OptionsDialogMsg struct {
+	Confirmed bool
+	Choice    int
+	Options   []ChecklistItem // Checked options of the choice
+}
+
+// NewOptionsDialog creates a new options dialog
+func NewOptionsDialog(title string) *OptionsDialog {
+	return &OptionsDialog{
+		title:  title,
+		height: 10,
+	}
+}
+
+// Show shows the dialog with the given choices, starting at choice. Options
+// keep the checks they come with.
+func (d *OptionsDialog) Show(choices []OptionsChoice, choice int) {
+	d.choices = choices
+	d.choice = min(max(choice, 0), len(choices)-1)
+	d.cursor = 0
+	d.scroll = 0
+	d.visible = true
+}
+
+// Hide hides the dialog
+func (d *OptionsDialog) Hide() {
+	d.visible = false
+}
+
+// IsVisible returns whether the dialog is visible
+func (d *OptionsDialog) IsVisible() bool {
+	return d.visible
+}
+
+// SetHeight sets the number of visible option rows
+func (d *OptionsDialog) SetHeight(height int) {
+	d.height = max(height, 3)
+}
+
+// options returns the options of the current choice
+func (d *OptionsDialog) options() []ChecklistItem {
+	return d.choices[d.choice].Options
+}
+
+// Update handles dialog input
+func (d *OptionsDialog) Update(msg tea.Msg) (*OptionsDialog, tea.Cmd) {
+	if !d.visible {
+		return d, nil
+	}
+
+	options := d.options()
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			d.cursor = max(d.cursor-1, 0)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			d.cursor = max(min(d.cursor+1, len(options)-1), 0)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("left", "h", "right", "l", "tab"))):
+			if len(d.choices) > 1 {
+				delta := 1
+				if msg.String() == "left" || msg.String() == "h" {
+					delta = len(d.choices) - 1
+				}
+				d.choice = (d.choice + delta) % len(d.choices)
+				d.cursor, d.scroll = 0, 0
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys(" ", "space", "x"))):
+			if d.cursor < len(options) {
+				options[d.cursor].Checked = !options[d.cursor].Checked
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			d.visible = false
+			result := OptionsDialogMsg{Confirmed: true, Choice: d.choice}
+			for _, option := range options {
+				if option.Checked {
+					result.Options = append(result.Options, option)
+				}
+			}
+			return d, func() tea.Msg { return result }
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			d.visible = false
+			return d, func() tea.Msg {
+				return OptionsDialogMsg{Confirmed: false}
+			}
+		}
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			d.cursor = max(d.cursor-1, 0)
+		case tea.MouseButtonWheelDown:
+			d.cursor = max(min(d.cursor+1, len(options)-1), 0)
+		}
+	}
+
+	// Keep the cursor visible
+	if d.cursor < d.scroll {
+		d.scroll = d.cursor
+	}
+	if d.cursor >= d.scroll+d.height {
+		d.scroll = d.cursor - d.height + 1
+	}
+
+	return d, nil
+}
+
+// View renders the dialog
+func (d *OptionsDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	sections := []string{styles.DialogTitleStyle.Render(d.title)}
+	if len(d.choices) > 1 {
+		var tabs []string
+		for i, choice := range d.choices {
+			if i == d.choice {
+				tabs = append(tabs, styles.DialogButtonActiveStyle.Render(choice.Label))
+			} else {
+				tabs = append(tabs, styles.DialogButtonStyle.Render(choice.Label))
+			}
+		}
+		sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top, tabs...), "")
+	}
+	choice := d.choices[d.choice]
+	sections = append(sections, styles.ValueStyle.Render(choice.Message), "")
+
+	options := choice.Options
+	var rows []string
+	end := min(d.scroll+d.height, len(options))
+	for i := d.scroll; i < end; i++ {
+		option := options[i]
+		check := "[ ]"
+		if option.Checked {
+			check = "[x]"
+		}
+		row := fmt.Sprintf("%s %-24s %s", check, option.Label, styles.DimStyle.Render(option.Detail))
+		if i == d.cursor {
+			row = styles.SelectedStyle.Render("▶ ") + row
+		} else {
+			row = "  " + row
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(optionsDialogWidth-4).Render(row))
+	}
+	if d.scroll > 0 {
+		rows = append([]string{styles.DimStyle.Render(fmt.Sprintf("  ↑ %d above", d.scroll))}, rows...)
+	}
+	if end < len(options) {
+		rows = append(rows, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(options)-end)))
+	}
+	if len(options) == 0 {
+		rows = append(rows, styles.DimStyle.Render("No options"))
+	}
+	sections = append(sections, strings.Join(rows, "\n"), "")
+
+	help := "Space: Toggle • Enter: Confirm • Esc: Cancel"
+	if len(d.choices) > 1 {
+		help = "←/→: Switch • " + help
+	}
+	sections = append(sections, styles.DimStyle.Render(help))
+
+	return styles.DialogBoxStyle.Width(optionsDialogWidth).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// Overlay renders the dialog as an overlay on top of content
+func (d *OptionsDialog) Overlay(content string, width, height int) string {
+	if !d.visible {
+		return content
+	}
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		d.View(),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(styles.Muted),
+	)
+}
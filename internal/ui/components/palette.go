@@ -0,0 +1,236 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+	"github.com/sahilm/fuzzy"
+)
+
+// PaletteItem is an entry in the command palette
+type PaletteItem struct {
+	Title string // Matched against the query
+	Kind  string // Group shown next to the title, like "Action" or "Package"
+	Keys  string // Key binding that does the same, if any
+}
+
+// PaletteMsg is sent when an item is chosen from the palette
+type PaletteMsg struct {
+	Index int // Position of the item in the slice passed to Show
+}
+
+// Palette is an overlay that fuzzy-matches a query against its items
+type Palette struct {
+	input   textinput.Model
+	items   []PaletteItem
+	matches fuzzy.Matches // Items matching the query, best first
+	cursor  int
+	scroll  int
+	height  int // Visible item rows
+	width   int // Box width, border excluded
+	visible bool
+}
+
+// NewPalette creates a new command palette
+func NewPalette() *Palette {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "Type an action, package or search..."
+	input.CharLimit = 100
+
+	return &Palette{input: input, height: 10, width: 70}
+}
+
+// Show opens the palette with the given items and an empty query
+func (p *Palette) Show(items []PaletteItem) tea.Cmd {
+	p.items = items
+	p.input.SetValue("")
+	p.filter()
+	p.visible = true
+	return p.input.Focus()
+}
+
+// Hide hides the palette
+func (p *Palette) Hide() {
+	p.visible = false
+	p.input.Blur()
+}
+
+// IsVisible returns whether the palette is visible
+func (p *Palette) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize fits the palette to a width x height screen
+func (p *Palette) SetSize(width, height int) {
+	p.width = max(min(width-4, 80), 30)
+	p.height = max(height-12, 3)
+}
+
+// Update handles palette input
+func (p *Palette) Update(msg tea.Msg) (*Palette, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "ctrl+p"))):
+			p.Hide()
+			return p, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if p.cursor >= len(p.matches) {
+				return p, nil
+			}
+			index := p.matches[p.cursor].Index
+			p.Hide()
+			return p, func() tea.Msg {
+				return PaletteMsg{Index: index}
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "ctrl+k"))):
+			p.move(-1)
+			return p, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "ctrl+j"))):
+			p.move(1)
+			return p, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgup"))):
+			p.move(-p.height)
+			return p, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgdown"))):
+			p.move(p.height)
+			return p, nil
+		}
+
+		query := p.input.Value()
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		if p.input.Value() != query {
+			p.filter()
+		}
+		return p, cmd
+
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			p.move(-1)
+		case tea.MouseButtonWheelDown:
+			p.move(1)
+		}
+	}
+
+	return p, nil
+}
+
+// filter matches the query against the items. An empty query lists every
+// item in its original order.
+func (p *Palette) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	if query == "" {
+		p.matches = make(fuzzy.Matches, len(p.items))
+		for i, item := range p.items {
+			p.matches[i] = fuzzy.Match{Str: item.Title, Index: i}
+		}
+	} else {
+		titles := make([]string, len(p.items))
+		for i, item := range p.items {
+			titles[i] = item.Title
+		}
+		p.matches = fuzzy.Find(query, titles)
+	}
+	p.cursor, p.scroll = 0, 0
+}
+
+// move moves the cursor by delta, keeping it visible
+func (p *Palette) move(delta int) {
+	p.cursor = min(max(p.cursor+delta, 0), max(len(p.matches)-1, 0))
+	if p.cursor < p.scroll {
+		p.scroll = p.cursor
+	}
+	if p.cursor >= p.scroll+p.height {
+		p.scroll = p.cursor - p.height + 1
+	}
+}
+
+// View renders the palette box
+func (p *Palette) View() string {
+	if !p.visible {
+		return ""
+	}
+	style := styles.DialogBoxStyle.Width(p.width)
+	inner := p.width - style.GetHorizontalPadding()
+
+	p.input.Width = inner - lipgloss.Width(p.input.Prompt) - 1
+	rows := []string{styles.DialogTitleStyle.Render("Command Palette"), p.input.View(), ""}
+
+	end := min(p.scroll+p.height, len(p.matches))
+	for i := p.scroll; i < end; i++ {
+		rows = append(rows, p.renderRow(p.matches[i], i == p.cursor, inner))
+	}
+	switch {
+	case len(p.matches) == 0:
+		rows = append(rows, styles.DimStyle.Render("No matches"))
+	case end < len(p.matches):
+		rows = append(rows, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(p.matches)-end)))
+	}
+
+	rows = append(rows, "", styles.DimStyle.Render("↑/↓: Select • Enter: Run • Esc: Close"))
+	return style.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// renderRow renders one item: the title with the matched characters bold,
+// its kind, and its key binding at the right edge
+func (p *Palette) renderRow(match fuzzy.Match, selected bool, width int) string {
+	item := p.items[match.Index]
+
+	matched := make(map[int]bool, len(match.MatchedIndexes))
+	for _, i := range match.MatchedIndexes {
+		matched[i] = true
+	}
+	titleStyle := styles.ValueStyle
+	if selected {
+		titleStyle = styles.SelectedStyle
+	}
+	var title strings.Builder
+	for i, r := range item.Title {
+		if matched[i] {
+			title.WriteString(titleStyle.Bold(true).Underline(true).Render(string(r)))
+		} else {
+			title.WriteString(titleStyle.Render(string(r)))
+		}
+	}
+
+	prefix := "  "
+	if selected {
+		prefix = styles.SelectedStyle.Render("▶ ")
+	}
+	left := prefix + title.String() + "  " + styles.DimStyle.Render(item.Kind)
+	right := ""
+	if item.Keys != "" {
+		right = styles.KeyStyle.Render(item.Keys)
+	}
+	gap := width - lipgloss.Width(left) - lipgloss.Width(right)
+	if gap < 1 {
+		return lipgloss.NewStyle().MaxWidth(width).Render(left)
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+// Overlay renders the palette on top of content, centered on the screen
+func (p *Palette) Overlay(content string, width, height int) string {
+	if !p.visible {
+		return content
+	}
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		p.View(),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(styles.Muted),
+	)
+}
@@ -0,0 +1,25 @@
+package components
+
+import "math"
+
+// Zone is a rectangle of the screen in terminal cells, for hit-testing mouse
+// events against what was last rendered
+type Zone struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Contains reports whether the cell at x, y is inside the zone
+func (z Zone) Contains(x, y int) bool {
+	return x >= z.X && x < z.X+z.Width && y >= z.Y && y < z.Y+z.Height
+}
+
+// centerOffset returns where lipgloss.Place puts content of size when
+// centering it in total cells
+func centerOffset(total, size int) int {
+	gap := total - size
+	if gap <= 0 {
+		return 0
+	}
+	return gap - int(math.Round(float64(gap)*0.5))
+}
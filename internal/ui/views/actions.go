@@ -0,0 +1,66 @@
+package views
+
+import "github.com/lazar0169/brewst/internal/brew"
+
+// Action requests. Views send these instead of running brew themselves, and
+// the app confirms, runs and reports them the same way for every view.
+type (
+	NavigateToDetailsMsg struct{}
+	RequestInstallMsg    struct{ Package brew.Package }
+	RequestReinstallMsg  struct{ Package brew.Package }
+	RequestUninstallMsg  struct{ Package brew.Package }
+	RequestUpgradeMsg    struct{ Packages []brew.OutdatedPackage }
+	RequestUpgradeAllMsg struct{ Packages []brew.OutdatedPackage } // Every outdated package, the auto-upgrade policy picks from them
+	TogglePinMsg         struct {
+		PackageName string
+		Pinned      bool // Pinned before the toggle
+	}
+	RequestLinkMsg struct {
+		Package brew.Package
+		Version string // Installed version to link in place of the linked one
+	}
+	RequestCleanupMsg    struct{ Packages []string } // Approved in a preview, runs without asking again
+	RequestAutoremoveMsg struct {
+		Packages []string // Unused dependencies approved in a preview
+		All      bool     // Run brew autoremove rather than uninstalling each
+	}
+	RequestTapMsg  struct{ Tap string }
+	RequestBrewMsg struct{ Args []string } // A brew command as typed, like a doctor fix
+)
+
+// ActionStartedMsg is sent to the dashboard and the current view when a
+// confirmed action starts running
+type ActionStartedMsg struct {
+	Op      string // Operation for the logs, like "install jq"
+	Message string // Progress message, like "Installing jq..."
+}
+
+// ActionOutputMsg carries a line of output of a running action, sent like
+// ActionStartedMsg
+type ActionOutputMsg struct {
+	Op   string
+	Line string
+}
+
+// ActionFinishedMsg is sent to the dashboard and the current view when an
+// action is done. The app refreshes the package lists after a success.
+type ActionFinishedMsg struct {
+	Op   string
+	Msg  string // Success message, only meaningful when Err is nil
+	Err  error
+	Logs []string // Hook output
+}
+
+// Command is an action a view offers in the command palette
+type Command struct {
+	Name  string // Sent back in CommandMsg, like "upgrade-all"
+	Title string // Shown in the palette, like "Upgrade all outdated packages"
+	Keys  string // Key binding in the view that does the same
+}
+
+// CommandMsg runs one of a view's commands. Arg carries input some commands
+// take, like the query of a search.
+type CommandMsg struct {
+	Name string
+	Arg  string
+}
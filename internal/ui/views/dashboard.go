@@ -3,6 +3,7 @@ package views
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/sbom"
 	"github.com/lazar0169/brewst/internal/state"
 	"github.com/lazar0169/brewst/internal/ui/components"
 	"github.com/lazar0169/brewst/internal/ui/styles"
@@ -25,15 +27,18 @@ const (
 	PanelInstalled PanelType = iota
 	PanelSearch
 	PanelDependencies
+	PanelLogs
 )
 
 // DashboardView shows everything at once
 type DashboardView struct {
 	client brew.Client
 	state  *state.State
+	config *state.Config
 
 	// Panels
 	installedList list.Model
+	filterInput   textinput.Model
 	searchInput   textinput.Model
 	searchResults []brew.Package
 
@@ -49,6 +54,7 @@ type DashboardView struct {
 	installedScroll int // Scroll positions
 	searchScroll    int
 	depScroll       int // Dependency scroll
+	savedFilterIdx  int // Position when cycling saved filters
 
 	// Debouncing for package info loading
 	pendingPackage *brew.Package // Package waiting to be loaded
@@ -63,16 +69,26 @@ type DashboardView struct {
 	dialog *components.Dialog
 	pendingAction string // Track what action is pending confirmation
 
+	// Dry-run preview for cleanup and autoremove
+	checklist    *components.ChecklistDialog
+	previewItems []brew.CleanupItem
+
 	// Logs
-	logs       []string // Log messages
-	logsScroll int      // Scroll position in logs
+	logs       *logPanel
+	newUpdates int // New updates count last reported in the logs
+
+	// Panel sizes, resizable with < > + -
+	splits layoutSplits
+
+	// Lines above each panel's first list row when last drawn, for mouse hit-testing
+	rowsTop map[PanelType]int
 
 	width  int
 	height int
 }
 
 // NewDashboardView creates a new dashboard view
-func NewDashboardView(client brew.Client, state *state.State) *DashboardView {
+func NewDashboardView(client brew.Client, state *state.State, config *state.Config) *DashboardView {
 	// Installed list
 	installedDelegate := list.NewDefaultDelegate()
 	installedDelegate.ShowDescription = false
@@ -83,6 +99,12 @@ func NewDashboardView(client brew.Client, state *state.State) *DashboardView {
 	installedList.SetShowHelp(false)
 	installedList.SetShowTitle(false)
 
+	// Filter bar for the installed list
+	filterInput := textinput.New()
+	filterInput.Prompt = "/ "
+	filterInput.Placeholder = "type:cask outdated pinned tap:user/repo name~py size>100M fav"
+	filterInput.CharLimit = 200
+
 	// Search input
 	searchInput := textinput.New()
 	searchInput.Placeholder = "Search packages..."
@@ -99,11 +121,17 @@ func NewDashboardView(client brew.Client, state *state.State) *DashboardView {
 	return &DashboardView{
 		client:        client,
 		state:         state,
+		config:        config,
 		installedList: installedList,
+		filterInput:   filterInput,
 		searchInput:   searchInput,
 		focusedPanel:  PanelInstalled,
 		spinner:       s,
 		dialog:        dialog,
+		checklist:     components.NewChecklistDialog("Preview"),
+		logs:          newLogPanel(),
+		splits:        defaultSplits(),
+		rowsTop:       make(map[PanelType]int),
 	}
 }
 
@@ -111,6 +139,17 @@ func NewDashboardView(client brew.Client, state *state.State) *DashboardView {
 func (v *DashboardView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
+	v.keepSelectionVisible()
+}
+
+// CapturingInput reports whether a text input currently owns the keyboard
+func (v *DashboardView) CapturingInput() bool {
+	return v.searchInput.Focused() || v.filterInput.Focused() || v.logs.search.Focused()
+}
+
+// HandlesMouse reports that the dashboard handles clicks and the wheel itself
+func (v *DashboardView) HandlesMouse() bool {
+	return true
 }
 
 // Init initializes the view
@@ -119,6 +158,7 @@ func (v *DashboardView) Init() tea.Cmd {
 	v.operationInProgress = true
 	v.operationMessage = "Loading packages..."
 	v.addLog("→ Loading installed packages...")
+	v.addLog("→ Calculating disk usage...")
 
 	v.updateInstalledList()
 
@@ -151,42 +191,63 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return v, tea.Batch(cmds...)
 	}
 
+	if v.checklist.IsVisible() {
+		var cmd tea.Cmd
+		v.checklist, cmd = v.checklist.Update(msg)
+		return v, cmd
+	}
+
 	switch msg := msg.(type) {
 	case components.DialogMsg:
 		if msg.Confirmed {
 			// Execute the pending action
 			switch v.pendingAction {
-			case "install":
-				if v.selectedPkg != nil {
-					return v, v.installPackage(v.selectedPkg)
-				}
-			case "uninstall":
-				if v.selectedPkg != nil {
-					return v, v.uninstallPackage(v.selectedPkg)
-				}
-			case "upgrade":
-				if v.selectedPkg != nil {
-					return v, v.upgradePackage(v.selectedPkg.Name)
-				}
-			case "upgradeAll":
-				return v, v.upgradeAll()
 			case "doctor":
 				return v, v.runDoctor()
-			case "cleanup":
-				return v, v.runCleanup()
-			case "autoremove":
-				return v, v.runAutoremove()
 			}
 		}
 		v.pendingAction = ""
 		return v, nil
 
+	case components.ChecklistDialogMsg:
+		action := v.pendingAction
+		v.pendingAction = ""
+		if !msg.Confirmed {
+			v.addLog(fmt.Sprintf("Cancelled brew %s", action))
+			return v, nil
+		}
+		return v, v.runApproved(action, msg.Items)
+
+	case CleanupPreviewMsg:
+		v.operationInProgress = false
+		v.operationMessage = ""
+		cmd := v.showPreview(msg.Action, msg.Items)
+		v.logs.end()
+		return v, cmd
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		v.spinner, cmd = v.spinner.Update(msg)
 		return v, cmd
 
+	case tea.MouseMsg:
+		return v, v.handleMouse(msg)
+
 	case tea.KeyMsg:
+		if v.filterInput.Focused() {
+			return v, v.handleFilterKey(msg)
+		}
+
+		if v.logs.search.Focused() {
+			return v, v.logs.handleSearchKey(msg, v.getLogsVisibleLines())
+		}
+
+		if v.focusedPanel == PanelLogs {
+			if cmd, handled := v.handleLogsKey(msg); handled {
+				return v, cmd
+			}
+		}
+
 		if v.searchInput.Focused() {
 			switch msg.String() {
 			case "esc":
@@ -282,6 +343,8 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case PanelSearch:
 				v.focusedPanel = PanelDependencies
 			case PanelDependencies:
+				v.focusedPanel = PanelLogs
+			case PanelLogs:
 				v.focusedPanel = PanelInstalled
 			}
 			return v, nil
@@ -289,75 +352,164 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			if v.focusedPanel == PanelSearch && len(v.searchResults) > 0 && !v.searchInput.Focused() {
 				if v.selectedPkg != nil && !v.selectedPkg.Installed {
-					v.pendingAction = "install"
 					v.searchInput.Blur()
-					v.dialog.SetMessage(fmt.Sprintf("Install %s?", v.selectedPkg.Name))
-					v.dialog.Show()
-					return v, nil
+					pkg := *v.selectedPkg
+					return v, func() tea.Msg { return RequestInstallMsg{Package: pkg} }
 				}
 			}
+			if v.focusedPanel == PanelInstalled {
+				return v, v.showDetails()
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("i"))):
+			if v.focusedPanel == PanelInstalled || v.focusedPanel == PanelSearch {
+				return v, v.showDetails()
+			}
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("u"))):
 			if v.focusedPanel == PanelInstalled && v.selectedPkg != nil && v.selectedPkg.Outdated {
-				v.pendingAction = "upgrade"
-				v.searchInput.Blur()
-				v.dialog.SetMessage(fmt.Sprintf("Upgrade %s?", v.selectedPkg.Name))
-				v.dialog.Show()
-				return v, nil
+				outdated, ok := v.state.GetOutdatedPackage(v.selectedPkg.Name)
+				if !ok {
+					outdated = brew.OutdatedPackage{Name: v.selectedPkg.Name, Type: v.selectedPkg.Type, CurrentVersion: v.selectedPkg.Version}
+				}
+				return v, func() tea.Msg { return RequestUpgradeMsg{Packages: []brew.OutdatedPackage{outdated}} }
 			}
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("x"))):
 			if v.focusedPanel == PanelInstalled && v.selectedPkg != nil {
-				v.pendingAction = "uninstall"
-				v.searchInput.Blur()
-				v.dialog.SetMessage(fmt.Sprintf("Uninstall %s?", v.selectedPkg.Name))
-				v.dialog.Show()
-				return v, nil
+				pkg := *v.selectedPkg
+				return v, func() tea.Msg { return RequestUninstallMsg{Package: pkg} }
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("p"))):
+			if v.focusedPanel == PanelInstalled && v.selectedPkg != nil {
+				name, pinned := v.selectedPkg.Name, v.selectedPkg.Pinned
+				return v, func() tea.Msg { return TogglePinMsg{PackageName: name, Pinned: pinned} }
 			}
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("U"))):
 			if v.focusedPanel == PanelInstalled {
-				outdatedCount := v.state.GetOutdatedCount()
-				if outdatedCount > 0 {
-					v.pendingAction = "upgradeAll"
-					v.searchInput.Blur()
-					v.dialog.SetMessage(fmt.Sprintf("Upgrade all %d outdated packages?", outdatedCount))
-					v.dialog.Show()
+				return v, v.requestUpgradeAll()
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("/"))):
+			if v.focusedPanel == PanelInstalled {
+				v.savedFilterIdx = -1
+				if filter := v.state.GetFilter(); filter != nil {
+					v.filterInput.SetValue(filter.Query)
+				} else {
+					v.filterInput.SetValue("")
+				}
+				v.filterInput.CursorEnd()
+				return v, v.filterInput.Focus()
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			if v.focusedPanel == PanelInstalled && v.state.GetFilter() != nil {
+				return v, v.applyFilter("")
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("f"))):
+			if v.focusedPanel == PanelInstalled && v.selectedPkg != nil {
+				name := v.selectedPkg.Name
+				v.state.ToggleFavorite(name)
+				if err := state.SaveFavorites(v.state.Favorites); err != nil {
+					v.addLog("Error: failed to save favorites: " + err.Error())
+				}
+				if v.state.IsFavorite(name) {
+					v.addLog(fmt.Sprintf("★ Added %s to favorites", name))
+				} else {
+					v.addLog(fmt.Sprintf("☆ Removed %s from favorites", name))
 				}
 				return v, nil
 			}
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("s"))):
+			if v.focusedPanel == PanelInstalled {
+				v.toggleSortBySize()
+				return v, nil
+			}
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
 			return v, v.refresh()
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("<", ">"))):
+			steps := 1
+			if msg.String() == "<" {
+				steps = -1
+			}
+			v.splits.resizeColumns(steps)
+			v.keepSelectionVisible()
+			return v, nil
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("+", "=", "-"))):
+			steps := 1
+			if msg.String() == "-" {
+				steps = -1
+			}
+			v.splits.resizePanel(v.focusedPanel, steps)
+			v.keepSelectionVisible()
+			return v, nil
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("d"))):
-			v.pendingAction = "doctor"
 			v.searchInput.Blur()
-			v.dialog.SetMessage("Run brew doctor to check for problems?")
-			v.dialog.Show()
+			v.confirmDoctor()
 			return v, nil
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("c"))):
-			v.pendingAction = "cleanup"
 			v.searchInput.Blur()
-			v.dialog.SetMessage("Run brew cleanup to remove old versions?")
-			v.dialog.Show()
-			return v, nil
+			return v, v.previewCleanup()
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
-			v.pendingAction = "autoremove"
 			v.searchInput.Blur()
-			v.dialog.SetMessage("Run brew autoremove to uninstall unused dependencies?")
-			v.dialog.Show()
-			return v, nil
+			return v, v.previewAutoremove()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("e"))):
+			v.searchInput.Blur()
+			return v, v.exportSBOM()
 		}
 
+	case CommandMsg:
+		return v, v.runCommand(msg)
+
 	case DebouncedLoadMsg:
 		if msg.id == v.debounceID && msg.pkg != nil {
 			return v, v.loadPackageInfo(msg.pkg)
 		}
 		return v, nil
 
+	case PolicyBlockedMsg:
+		v.operationInProgress = false
+		v.operationMessage = ""
+		v.addLog(blockedLog(msg.Reason))
+		v.logs.end()
+		return v, nil
+
+	case ActionStartedMsg:
+		v.operationInProgress = true
+		v.operationMessage = msg.Message
+		v.logs.begin(msg.Op)
+		v.addLog("→ " + msg.Message)
+		return v, nil
+
+	case ActionOutputMsg:
+		v.addLog(msg.Line)
+		return v, nil
+
+	case ActionFinishedMsg:
+		v.operationInProgress = false
+		v.operationMessage = ""
+		for _, line := range msg.Logs {
+			v.addLog(line)
+		}
+		if msg.Err != nil {
+			v.addLog("Error: " + msg.Err.Error())
+		} else {
+			v.addLog("✓ " + msg.Msg)
+		}
+		v.logs.end()
+		return v, nil
+
 	case PackageInfoLoadedMsg:
 		v.packageInfo = msg.Info
 		v.loadingInfo = false
@@ -391,6 +543,22 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return v, nil
 
 	case OutdatedLoadedMsg:
+		if msg.Background {
+			// Refresh markers quietly, keeping the selection and any running operation
+			name := ""
+			if v.selectedPkg != nil && v.focusedPanel == PanelInstalled {
+				name = v.selectedPkg.Name
+			}
+			v.updateInstalledList()
+			if name != "" {
+				v.restoreSelection(name)
+			}
+			if newUpdates := v.state.GetNewUpdates(); newUpdates > 0 && newUpdates != v.newUpdates {
+				v.addLog("⚠ " + NewUpdatesText(newUpdates))
+				v.newUpdates = newUpdates
+			}
+			return v, nil
+		}
 		v.updateInstalledList()
 		v.operationInProgress = false
 		v.operationMessage = ""
@@ -407,10 +575,25 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return v, nil
 
+	case DiskUsageLoadedMsg:
+		name := ""
+		if v.selectedPkg != nil {
+			name = v.selectedPkg.Name
+		}
+		v.updateInstalledList()
+		v.restoreSelection(name)
+		total, reclaimable := v.state.GetDiskUsage()
+		v.addLog(fmt.Sprintf("✓ Disk usage: %s (%s reclaimable)", brew.FormatSize(total), brew.FormatSize(reclaimable)))
+		return v, nil
+
 	case SuccessMsgView:
 		v.operationInProgress = false
 		v.operationMessage = ""
+		for _, line := range msg.Logs {
+			v.addLog(line)
+		}
 		v.addLog("✓ " + msg.Msg)
+		v.logs.end()
 		v.state.SetSuccess(msg.Msg)
 		return v, func() tea.Msg {
 			return RefreshPackagesMsg{}
@@ -421,19 +604,38 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.searching = false
 		v.operationInProgress = false
 		v.operationMessage = ""
+		for _, line := range msg.Logs {
+			v.addLog(line)
+		}
 		v.addLog("Error: " + msg.Err.Error())
+		v.logs.end()
 		v.state.SetError(msg.Err)
 		return v, nil
 
+	case ExportedMsg:
+		v.addLog(fmt.Sprintf("✓ Exported %s to %s", msg.What, msg.Path))
+		v.logs.end()
+		return v, nil
+
 	case DoctorOutputMsg:
 		v.operationInProgress = false
 		v.operationMessage = ""
-		for _, line := range msg.Lines {
-			if strings.TrimSpace(line) != "" {
-				v.addLog(line)
+		for _, d := range msg.Diagnostics {
+			prefix := "⚠"
+			if d.Severity == brew.SeverityError {
+				prefix = "Error:"
 			}
+			v.addLog(fmt.Sprintf("%s [%s] %s", prefix, d.Severity, d.Title))
+			for _, command := range d.Commands {
+				v.addLog("    fix: " + command)
+			}
+		}
+		if len(msg.Diagnostics) == 0 {
+			v.addLog("✓ Your system is ready to brew")
+		} else {
+			v.addLog(fmt.Sprintf("✓ Doctor found %d problems (press 6 for details and fixes)", len(msg.Diagnostics)))
 		}
-		v.addLog("✓ Doctor completed")
+		v.logs.end()
 		return v, nil
 	}
 
@@ -464,33 +666,19 @@ func (v *DashboardView) View() string {
 		return "Loading..."
 	}
 
-	// Layout: 50% left (installed list), 50% right (search + dependency tree + logs)
-	statusHeight := 1
-	contentHeight := v.height - statusHeight
-
-	// Account for panel borders and padding in width calculation
-	leftWidth := (v.width / 2) - 2
-	rightWidth := (v.width / 2) - 2
-
-	// Right side split: 35% search, 35% dependency tree, 30% logs
-	searchHeight := int(float64(contentHeight) * 0.35)
-	depTreeHeight := int(float64(contentHeight) * 0.35)
-	logsHeight := contentHeight - searchHeight - depTreeHeight
-
-	// Render panels
-	installedPanel := v.renderInstalledPanel(leftWidth, contentHeight)
-	searchPanel := v.renderSearchPanel(rightWidth, searchHeight)
-	depTreePanel := v.renderDependencyTreePanel(rightWidth, depTreeHeight)
-	logsPanel := v.renderLogsPanel(rightWidth, logsHeight)
-
-	// Combine right side panels vertically
-	rightSide := lipgloss.JoinVertical(lipgloss.Left, searchPanel, depTreePanel, logsPanel)
-
-	// Combine left and right horizontally
-	panels := lipgloss.JoinHorizontal(lipgloss.Top, installedPanel, rightSide)
+	layout := v.layout()
+	var columns []string
+	for _, column := range layout.columns {
+		var panels []string
+		for _, panel := range column {
+			panels = append(panels, v.renderPanel(panel, layout.rects[panel]))
+		}
+		columns = append(columns, lipgloss.JoinVertical(lipgloss.Left, panels...))
+	}
+	panels := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
 
-	// Status bar
-	statusBar := v.renderStatusBar()
+	// Status bar, cut to its one line on narrow terminals
+	statusBar := lipgloss.NewStyle().MaxHeight(layout.status.Height).Render(v.renderStatusBar())
 
 	content := lipgloss.JoinVertical(lipgloss.Left, panels, statusBar)
 
@@ -498,41 +686,73 @@ func (v *DashboardView) View() string {
 	if v.dialog.IsVisible() {
 		content = v.dialog.Overlay(content, v.width, v.height)
 	}
+	if v.checklist.IsVisible() {
+		v.checklist.SetHeight(v.height - 14)
+		content = v.checklist.Overlay(content, v.width, v.height)
+	}
 
 	return content
 }
 
-func (v *DashboardView) renderInstalledPanel(width, height int) string {
-	panelStyle := styles.PanelStyle
-	if v.focusedPanel == PanelInstalled {
-		panelStyle = styles.ActivePanelStyle
-	}
+func (v *DashboardView) layout() dashboardLayout {
+	return computeLayout(v.width, v.height, v.splits, v.focusedPanel)
+}
 
-	title := styles.PanelTitleStyle.Render(fmt.Sprintf("📦 Installed (%d)", v.state.GetInstalledCount()))
+// renderPanel renders a panel into its rectangle of the layout
+func (v *DashboardView) renderPanel(panel PanelType, rect components.Zone) string {
+	width, height := inner(rect)
+	var content string
+	switch panel {
+	case PanelInstalled:
+		content = v.renderInstalledPanel(width, height)
+	case PanelSearch:
+		content = v.renderSearchPanel(width, height)
+	case PanelDependencies:
+		content = v.renderDependencyTreePanel(width, height)
+	case PanelLogs:
+		content = v.renderLogsPanel(width, height)
+	}
+	return framePanel(rect, v.focusedPanel == panel, content)
+}
 
+func (v *DashboardView) renderInstalledPanel(width, height int) string {
 	// Render packages as table with Name, Version, Type
 	packages := v.state.GetFilteredPackages()
 	var lines []string
 
-	maxLines := height - 5 // title + border + header
-	if maxLines < 5 {
-		maxLines = 5
+	titleText := fmt.Sprintf("📦 Installed (%d)", v.state.GetInstalledCount())
+	if filter := v.state.GetFilter(); filter != nil {
+		titleText = fmt.Sprintf("📦 Installed (%d/%d) / %s", len(packages), v.state.GetInstalledCount(), filter.Query)
 	}
+	title := styles.PanelTitleStyle.Render(titleText)
+
+	if v.filterInput.Focused() {
+		v.filterInput.Width = width - 6
+		lines = append(lines, v.filterInput.View())
+	}
+
+	maxLines := v.getInstalledVisibleLines()
 
 	// Calculate column widths
-	// Account for: border (4), padding (2), prefix (2), status (2) = 10 total
-	contentWidth := width - 10
-	nameWidth := int(float64(contentWidth) * 0.5)  // 50% for name
-	versionWidth := int(float64(contentWidth) * 0.3) // 30% for version
-	typeWidth := int(float64(contentWidth) * 0.2) // 20% for type
+	// Account for: prefix (2), column gaps (4), status (2) = 8 total
+	contentWidth := width - 8
+	nameWidth := int(float64(contentWidth) * 0.45)    // 45% for name
+	versionWidth := int(float64(contentWidth) * 0.25) // 25% for version
+	sizeWidth := int(float64(contentWidth) * 0.12)    // 12% for size
+	typeWidth := int(float64(contentWidth) * 0.18)    // 18% for type
 
 	// Header row
-	header := fmt.Sprintf("  %-*s %-*s %-*s",
+	sizeHeader := "SIZE"
+	if v.state.SortBySize {
+		sizeHeader = "SIZE ↓"
+	}
+	header := fmt.Sprintf("  %-*s %-*s %*s %-*s",
 		nameWidth, "NAME",
 		versionWidth, "VERSION",
+		sizeWidth, sizeHeader,
 		typeWidth, "TYPE")
 	lines = append(lines, styles.DimStyle.Render(header))
-	lines = append(lines, styles.DimStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, styles.DimStyle.Render(strings.Repeat("─", width-4)))
 
 	// Calculate visible range based on scroll position
 	start := v.installedScroll
@@ -540,6 +760,7 @@ func (v *DashboardView) renderInstalledPanel(width, height int) string {
 	if end > len(packages) {
 		end = len(packages)
 	}
+	v.rowsTop[PanelInstalled] = lipgloss.Height(title) + len(lines)
 
 	for i := start; i < end; i++ {
 		pkg := packages[i]
@@ -572,20 +793,29 @@ func (v *DashboardView) renderInstalledPanel(width, height int) string {
 			version = version[:versionWidth-5] + "..."
 		}
 
+		size := "-"
+		if pkg.Size > 0 {
+			size = brew.FormatSize(pkg.Size)
+		}
+
 		// Status indicator
 		status := "✓"
 		if pkg.Outdated {
 			status = "⚠"
 		}
+		if severity, count := v.state.GetVulnerabilitySeverity(pkg.Name); count > 0 {
+			status += " " + vulnMarker(severity, count)
+		}
 
 		// Apply color to type
 		styledType := typeStyle.Render(fmt.Sprintf("%-*s", typeWidth, typeDisplay))
 
 		// Build final line with styled type
-		finalLine := fmt.Sprintf("%s %-*s %-*s %s %s",
+		finalLine := fmt.Sprintf("%s %-*s %-*s %*s %s %s",
 			prefix,
 			nameWidth, name,
 			versionWidth, version,
+			sizeWidth, size,
 			styledType,
 			status)
 
@@ -594,22 +824,30 @@ func (v *DashboardView) renderInstalledPanel(width, height int) string {
 
 	listContent := strings.Join(lines, "\n")
 	if len(packages) == 0 {
-		listContent = styles.DimStyle.Render("No packages installed")
+		if v.state.GetFilter() != nil {
+			listContent = lipgloss.JoinVertical(lipgloss.Left, listContent, styles.DimStyle.Render("No packages match the filter"))
+		} else {
+			listContent = styles.DimStyle.Render("No packages installed")
+		}
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, title, listContent)
 
-	return panelStyle.
-		Width(width).
-		Render(content)
+	// Disk usage summary
+	if total, reclaimable := v.state.GetDiskUsage(); total > 0 {
+		summary := fmt.Sprintf("💾 %s used", brew.FormatSize(total))
+		if reclaimable > 0 {
+			summary += fmt.Sprintf(" • %s reclaimable from old versions", brew.FormatSize(reclaimable))
+		}
+		// One line, as counted in getInstalledVisibleLines
+		summary = lipgloss.NewStyle().MaxWidth(width - 4).Render(styles.DimStyle.Render(summary))
+		content = lipgloss.JoinVertical(lipgloss.Left, content, "", summary)
+	}
+
+	return content
 }
 
 func (v *DashboardView) renderDependencyTreePanel(width, height int) string {
-	panelStyle := styles.PanelStyle
-	if v.focusedPanel == PanelDependencies {
-		panelStyle = styles.ActivePanelStyle
-	}
-
 	title := styles.PanelTitleStyle.Render("🌳 Dependencies")
 
 	var content strings.Builder
@@ -624,11 +862,7 @@ func (v *DashboardView) renderDependencyTreePanel(width, height int) string {
 		content.WriteString(styles.KeyStyle.Render(v.packageInfo.Name))
 		content.WriteString("\n")
 
-		maxLines := height - 6
-		if maxLines < 1 {
-			maxLines = 1
-		}
-
+		maxLines := v.getDependenciesVisibleLines()
 		deps := v.packageInfo.Dependencies
 
 		// Apply scrolling
@@ -637,6 +871,7 @@ func (v *DashboardView) renderDependencyTreePanel(width, height int) string {
 		if end > len(deps) {
 			end = len(deps)
 		}
+		v.rowsTop[PanelDependencies] = strings.Count(content.String(), "\n")
 
 		for i := start; i < end; i++ {
 			dep := deps[i]
@@ -664,60 +899,36 @@ func (v *DashboardView) renderDependencyTreePanel(width, height int) string {
 		}
 	}
 
-	return panelStyle.Width(width).Render(content.String())
+	return content.String()
 }
 
 func (v *DashboardView) renderLogsPanel(width, height int) string {
-	panelStyle := styles.PanelStyle
-
-	title := styles.PanelTitleStyle.Render("📋 Logs")
+	titleText := "📋 Logs"
+	if label := v.logs.filterLabel(); label != "" {
+		titleText += " / " + label
+	}
+	if v.logs.scroll > 0 {
+		titleText += fmt.Sprintf(" (↓ %d newer)", v.logs.scroll)
+	}
 
 	var content strings.Builder
-	content.WriteString(title)
+	content.WriteString(styles.PanelTitleStyle.Render(fitWidth(titleText, width)))
 	content.WriteString("\n")
 
-	if len(v.logs) == 0 {
-		content.WriteString(styles.DimStyle.Render("No logs yet"))
-	} else {
-		maxLines := height - 4
-		if maxLines < 1 {
-			maxLines = 1
-		}
-
-		// Show most recent logs (auto-scroll to bottom)
-		start := 0
-		if len(v.logs) > maxLines {
-			start = len(v.logs) - maxLines
-		}
-
-		for i := start; i < len(v.logs); i++ {
-			logLine := v.logs[i]
-			// Color code based on content
-			if strings.Contains(logLine, "Error") || strings.Contains(logLine, "error") {
-				content.WriteString(styles.ErrorStyle.Render(logLine))
-			} else if strings.Contains(logLine, "Success") || strings.Contains(logLine, "✓") {
-				content.WriteString(styles.SuccessMessageStyle.Render(logLine))
-			} else if strings.Contains(logLine, "Warning") || strings.Contains(logLine, "⚠") {
-				content.WriteString(styles.OutdatedStyle.Render(logLine))
-			} else {
-				content.WriteString(styles.DimStyle.Render(logLine))
-			}
-			content.WriteString("\n")
-		}
+	if v.logs.search.Focused() {
+		v.logs.search.Width = width - 4
+		content.WriteString(v.logs.search.View())
+		content.WriteString("\n")
 	}
+	content.WriteString(v.logs.render(width, v.getLogsVisibleLines()))
 
-	return panelStyle.Width(width).Render(content.String())
+	return content.String()
 }
 
 func (v *DashboardView) renderSearchPanel(width, height int) string {
-	panelStyle := styles.PanelStyle
-	if v.focusedPanel == PanelSearch {
-		panelStyle = styles.ActivePanelStyle
-	}
-
 	title := styles.PanelTitleStyle.Render("🔍 Search")
 
-	v.searchInput.Width = width - 6
+	v.searchInput.Width = width - 4
 
 	var content strings.Builder
 	content.WriteString(title)
@@ -731,16 +942,13 @@ func (v *DashboardView) renderSearchPanel(width, height int) string {
 		content.WriteString(styles.DimStyle.Render(fmt.Sprintf("(%d results)", len(v.searchResults))))
 		content.WriteString("\n")
 
-		maxLines := height - 7
-		if maxLines < 2 {
-			maxLines = 2
-		}
-
+		maxLines := v.getSearchVisibleLines()
 		start := v.searchScroll
 		end := start + maxLines
 		if end > len(v.searchResults) {
 			end = len(v.searchResults)
 		}
+		v.rowsTop[PanelSearch] = strings.Count(content.String(), "\n")
 
 		for i := start; i < end; i++ {
 			pkg := v.searchResults[i]
@@ -764,65 +972,7 @@ func (v *DashboardView) renderSearchPanel(width, height int) string {
 		}
 	}
 
-	return panelStyle.Width(width).Render(content.String())
-}
-
-
-func (v *DashboardView) renderPackageInfo() string {
-	if v.packageInfo == nil {
-		return ""
-	}
-
-	info := v.packageInfo
-	var sections []string
-
-	// Name and version
-	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Left,
-		styles.KeyStyle.Render("Name: "),
-		styles.ValueStyle.Render(info.Name),
-	))
-
-	if info.Version != "" {
-		sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Left,
-			styles.KeyStyle.Render("Version: "),
-			styles.ValueStyle.Render(info.Version),
-		))
-	}
-
-	// Type
-	pkgType := "Formula"
-	if info.Type == brew.TypeCask {
-		pkgType = "Cask"
-	}
-	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Left,
-		styles.KeyStyle.Render("Type: "),
-		styles.ValueStyle.Render(pkgType),
-	))
-
-	// Description
-	if info.Description != "" {
-		sections = append(sections, "")
-		sections = append(sections, styles.KeyStyle.Render("Description:"))
-		sections = append(sections, styles.ValueStyle.Render(info.Description))
-	}
-
-	// Homepage
-	if info.Homepage != "" {
-		sections = append(sections, "")
-		sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Left,
-			styles.KeyStyle.Render("Homepage: "),
-			styles.DimStyle.Render(info.Homepage),
-		))
-	}
-
-	// Dependencies
-	if len(info.Dependencies) > 0 {
-		sections = append(sections, "")
-		sections = append(sections, styles.KeyStyle.Render("Dependencies:"))
-		sections = append(sections, styles.ValueStyle.Render("  "+strings.Join(info.Dependencies, ", ")))
-	}
-
-	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	return content.String()
 }
 
 func (v *DashboardView) renderStatusBar() string {
@@ -834,25 +984,68 @@ func (v *DashboardView) renderStatusBar() string {
 
 	var parts []string
 
+	if newUpdates := v.state.GetNewUpdates(); newUpdates > 0 {
+		parts = append(parts, styles.OutdatedStyle.Render("⬆ "+NewUpdatesText(newUpdates)))
+	}
+
 	switch v.focusedPanel {
 	case PanelInstalled:
+		if v.filterInput.Focused() {
+			return styles.StatusBarStyle.Width(v.width).Render(strings.Join([]string{
+				"Enter: Apply filter",
+				"↑/↓: Saved filters",
+				"Ctrl+S: Save filter",
+				"Esc: Cancel",
+			}, " • "))
+		}
 		parts = append(parts, "u: Upgrade")
 		parts = append(parts, "x: Uninstall")
+		parts = append(parts, "p: Pin")
 		parts = append(parts, "U: Upgrade all")
+		parts = append(parts, "/: Filter")
+		parts = append(parts, "s: Sort by size")
+		parts = append(parts, "f: Favorite")
+		parts = append(parts, "i: Details")
 	case PanelSearch:
 		parts = append(parts, "Enter: Search/Install")
+		parts = append(parts, "i: Details")
+	case PanelLogs:
+		if v.logs.search.Focused() {
+			return styles.StatusBarStyle.Width(v.width).Render(strings.Join([]string{
+				"Enter: Search",
+				"Esc: Cancel",
+			}, " • "))
+		}
+		parts = append(parts, "↑/↓: Scroll")
+		parts = append(parts, "/: Search")
+		parts = append(parts, "n/N: Next/prev match")
+		parts = append(parts, "l: Level")
+		parts = append(parts, "o: Operation")
+		parts = append(parts, "e: Export logs")
 	}
 
 	parts = append(parts, "Tab: Switch")
+	parts = append(parts, "</>/+/-: Resize")
 	parts = append(parts, "d: Doctor")
 	parts = append(parts, "c: Cleanup")
 	parts = append(parts, "a: Autoremove")
+	if v.focusedPanel != PanelLogs {
+		parts = append(parts, "e: Export SBOM")
+	}
 	parts = append(parts, "r: Refresh")
 	parts = append(parts, "q: Quit")
 
 	return styles.StatusBarStyle.Width(v.width).Render(strings.Join(parts, " • "))
 }
 
+// NewUpdatesText describes how many updates appeared since brewst started
+func NewUpdatesText(count int) string {
+	if count == 1 {
+		return "1 new update since you opened brewst"
+	}
+	return fmt.Sprintf("%d new updates since you opened brewst", count)
+}
+
 func (v *DashboardView) updateInstalledList() {
 	packages := v.state.GetFilteredPackages()
 	items := make([]list.Item, len(packages))
@@ -862,6 +1055,190 @@ func (v *DashboardView) updateInstalledList() {
 	v.installedList.SetItems(items)
 }
 
+// handleFilterKey handles keys while the filter bar is focused
+func (v *DashboardView) handleFilterKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		v.filterInput.Blur()
+		return nil
+	case "enter":
+		return v.applyFilter(v.filterInput.Value())
+	case "up", "down":
+		saved := v.config.SavedFilters
+		if len(saved) == 0 {
+			return nil
+		}
+		if msg.String() == "up" {
+			v.savedFilterIdx--
+			if v.savedFilterIdx < 0 {
+				v.savedFilterIdx = len(saved) - 1
+			}
+		} else {
+			v.savedFilterIdx = (v.savedFilterIdx + 1) % len(saved)
+		}
+		v.filterInput.SetValue(saved[v.savedFilterIdx])
+		v.filterInput.CursorEnd()
+		return nil
+	case "ctrl+s":
+		v.saveFilter(v.filterInput.Value())
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.filterInput, cmd = v.filterInput.Update(msg)
+	return cmd
+}
+
+// applyFilter parses and activates a filter query, an empty query clears it
+func (v *DashboardView) applyFilter(query string) tea.Cmd {
+	filter, err := state.ParseFilter(query)
+	if err != nil {
+		v.addLog("Error: " + err.Error())
+		v.state.SetError(err)
+		return nil
+	}
+
+	v.filterInput.Blur()
+	if filter.IsEmpty() {
+		v.state.SetFilter(nil)
+		v.addLog("✓ Filter cleared")
+	} else {
+		v.state.SetFilter(filter)
+		v.addLog(fmt.Sprintf("✓ Filter applied: %s (%d matches)", filter.Query, len(v.state.GetFilteredPackages())))
+	}
+
+	v.updateInstalledList()
+	v.installedIndex = 0
+	v.installedScroll = 0
+	v.selectedPkg = nil
+	v.packageInfo = nil
+	packages := v.state.GetFilteredPackages()
+	if len(packages) > 0 {
+		v.selectedPkg = &packages[0]
+		return v.loadPackageInfo(&packages[0])
+	}
+	return nil
+}
+
+// saveFilter persists a filter query in the config
+func (v *DashboardView) saveFilter(query string) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+	if _, err := state.ParseFilter(query); err != nil {
+		v.addLog("Error: " + err.Error())
+		return
+	}
+
+	for _, saved := range v.config.SavedFilters {
+		if saved == query {
+			v.addLog("Filter already saved: " + query)
+			return
+		}
+	}
+
+	v.config.SavedFilters = append(v.config.SavedFilters, query)
+	if err := v.config.Save(); err != nil {
+		v.addLog("Error: failed to save config: " + err.Error())
+		return
+	}
+	v.addLog("✓ Saved filter: " + query)
+}
+
+// Commands returns the dashboard's actions for the command palette
+func (v *DashboardView) Commands() []Command {
+	return []Command{
+		{Name: "upgrade-all", Title: "Upgrade all outdated packages", Keys: "U"},
+		{Name: "cleanup", Title: "Clean up old versions and caches", Keys: "c"},
+		{Name: "autoremove", Title: "Remove unused dependencies", Keys: "a"},
+		{Name: "doctor", Title: "Run brew doctor", Keys: "d"},
+		{Name: "export-sbom", Title: "Export SBOM", Keys: "e"},
+		{Name: "export-logs", Title: "Export session log", Keys: "e (logs)"},
+		{Name: "sort-size", Title: "Toggle sorting by size", Keys: "s"},
+		{Name: "refresh", Title: "Refresh packages", Keys: "r"},
+	}
+}
+
+// runCommand runs a command from the palette the way its key binding does.
+// "search" searches for Arg, for the palette's recent searches, and "filter"
+// applies Arg as the installed filter.
+func (v *DashboardView) runCommand(msg CommandMsg) tea.Cmd {
+	v.searchInput.Blur()
+	switch msg.Name {
+	case "upgrade-all":
+		return v.requestUpgradeAll()
+	case "cleanup":
+		return v.previewCleanup()
+	case "autoremove":
+		return v.previewAutoremove()
+	case "doctor":
+		v.confirmDoctor()
+	case "export-sbom":
+		return v.exportSBOM()
+	case "export-logs":
+		return v.exportLogs()
+	case "sort-size":
+		v.toggleSortBySize()
+	case "refresh":
+		return v.refresh()
+	case "search":
+		v.focusedPanel = PanelSearch
+		v.searchInput.SetValue(msg.Arg)
+		return v.performSearch(msg.Arg)
+	case "filter":
+		v.focusedPanel = PanelInstalled
+		v.filterInput.SetValue(msg.Arg)
+		return v.applyFilter(msg.Arg)
+	}
+	return nil
+}
+
+// requestUpgradeAll asks the app to upgrade every outdated package the
+// auto-upgrade policy allows
+func (v *DashboardView) requestUpgradeAll() tea.Cmd {
+	outdated := v.state.GetOutdatedPackages()
+	if len(outdated) == 0 {
+		v.addLog("✓ All packages are up to date")
+		return nil
+	}
+	return func() tea.Msg {
+		return RequestUpgradeAllMsg{Packages: outdated}
+	}
+}
+
+func (v *DashboardView) confirmDoctor() {
+	v.pendingAction = "doctor"
+	v.dialog.SetMessage("Run brew doctor to check for problems?")
+	v.dialog.Show()
+}
+
+// toggleSortBySize switches the installed list between sorting by name and
+// by size, keeping the selection
+func (v *DashboardView) toggleSortBySize() {
+	name := ""
+	if v.selectedPkg != nil {
+		name = v.selectedPkg.Name
+	}
+	if v.state.ToggleSortBySize() {
+		v.addLog("Sorting installed packages by size")
+	} else {
+		v.addLog("Sorting installed packages by name")
+	}
+	v.updateInstalledList()
+	v.restoreSelection(name)
+}
+
+// showDetails opens the details view for the selected package
+func (v *DashboardView) showDetails() tea.Cmd {
+	if v.selectedPkg == nil {
+		return nil
+	}
+	pkg := *v.selectedPkg
+	v.state.SetSelectedPackage(&pkg)
+	return func() tea.Msg { return NavigateToDetailsMsg{} }
+}
+
 func (v *DashboardView) loadPackageInfo(pkg *brew.Package) tea.Cmd {
 	v.loadingInfo = true
 	return func() tea.Msg {
@@ -874,6 +1251,30 @@ func (v *DashboardView) loadPackageInfo(pkg *brew.Package) tea.Cmd {
 	}
 }
 
+// restoreSelection keeps the named package selected after the list changed
+func (v *DashboardView) restoreSelection(name string) {
+	packages := v.state.GetFilteredPackages()
+	v.installedIndex = 0
+	for i, pkg := range packages {
+		if pkg.Name == name {
+			v.installedIndex = i
+			break
+		}
+	}
+
+	visibleLines := v.getInstalledVisibleLines()
+	if v.installedIndex < v.installedScroll || v.installedIndex >= v.installedScroll+visibleLines {
+		v.installedScroll = v.installedIndex - visibleLines/2
+		if v.installedScroll < 0 {
+			v.installedScroll = 0
+		}
+	}
+
+	if v.focusedPanel == PanelInstalled && len(packages) > 0 {
+		v.selectedPkg = &packages[v.installedIndex]
+	}
+}
+
 func (v *DashboardView) updateSelectedPackage() {
 	switch v.focusedPanel {
 	case PanelInstalled:
@@ -909,6 +1310,7 @@ func (v *DashboardView) debouncedLoadPackageInfo(pkg *brew.Package) tea.Cmd {
 
 func (v *DashboardView) performSearch(query string) tea.Cmd {
 	v.searching = true
+	v.state.AddRecentSearch(query)
 	return func() tea.Msg {
 		ctx := context.Background()
 		results, err := v.client.Search(ctx, query)
@@ -920,68 +1322,195 @@ func (v *DashboardView) performSearch(query string) tea.Cmd {
 }
 
 func (v *DashboardView) addLog(msg string) {
-	v.logs = append(v.logs, msg)
-	// Keep only last 1000 lines
-	if len(v.logs) > 1000 {
-		v.logs = v.logs[len(v.logs)-1000:]
+	v.logs.add(levelOf(msg), msg)
+}
+
+// handleLogsKey handles the keys of the focused logs panel. Keys it doesn't
+// handle fall through to the dashboard.
+func (v *DashboardView) handleLogsKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	lines := v.getLogsVisibleLines()
+	switch msg.String() {
+	case "up", "k":
+		v.logs.scrollBy(1, lines)
+	case "down", "j":
+		v.logs.scrollBy(-1, lines)
+	case "pgup":
+		v.logs.scrollBy(lines, lines)
+	case "pgdown":
+		v.logs.scrollBy(-lines, lines)
+	case "g", "home":
+		v.logs.scrollBy(len(v.logs.entries), lines)
+	case "G", "end":
+		v.logs.scroll = 0
+	case "/":
+		v.logs.search.SetValue(v.logs.query)
+		v.logs.search.CursorEnd()
+		return v.logs.search.Focus(), true
+	case "n":
+		v.logs.findMatch(true, lines)
+	case "N":
+		v.logs.findMatch(false, lines)
+	case "esc":
+		if v.logs.query == "" {
+			return nil, false
+		}
+		v.logs.query = ""
+		v.logs.search.SetValue("")
+	case "l":
+		v.logs.cycleLevel()
+	case "o":
+		v.logs.cycleOp()
+	case "e":
+		return v.exportLogs(), true
+	default:
+		return nil, false
 	}
+	return nil, true
 }
 
-func (v *DashboardView) installPackage(pkg *brew.Package) tea.Cmd {
-	v.operationInProgress = true
-	v.operationMessage = fmt.Sprintf("Installing %s...", pkg.Name)
-	v.addLog(fmt.Sprintf("→ Installing %s...", pkg.Name))
-	return func() tea.Msg {
-		ctx := context.Background()
-		opts := brew.InstallOptions{Cask: pkg.Type == brew.TypeCask}
-		err := v.client.Install(ctx, pkg.Name, opts)
-		if err != nil {
-			return ErrorMsgView{Err: err}
+// wheelStep is how many lines one mouse wheel notch scrolls
+const wheelStep = 3
+
+// handleMouse focuses the panel under a click and selects the clicked row,
+// and scrolls the panel under the wheel
+func (v *DashboardView) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	layout := v.layout()
+	panel, ok := layout.panelAt(msg.X, msg.Y)
+	if !ok {
+		return nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		v.scrollPanel(panel, -wheelStep)
+	case tea.MouseButtonWheelDown:
+		v.scrollPanel(panel, wheelStep)
+	case tea.MouseButtonLeft:
+		// The filter bar and log search need Enter or Esc to close
+		if msg.Action != tea.MouseActionPress || v.filterInput.Focused() || v.logs.search.Focused() {
+			return nil
 		}
-		return SuccessMsgView{Msg: "Installed " + pkg.Name}
+		top := layout.rects[panel].Y + styles.PanelStyle.GetBorderTopSize() + styles.PanelStyle.GetPaddingTop()
+		return v.clickPanel(panel, msg.Y-top-v.rowsTop[panel])
 	}
+	return nil
 }
 
-func (v *DashboardView) uninstallPackage(pkg *brew.Package) tea.Cmd {
-	v.operationInProgress = true
-	v.operationMessage = fmt.Sprintf("Uninstalling %s...", pkg.Name)
-	v.addLog(fmt.Sprintf("→ Uninstalling %s...", pkg.Name))
-	return func() tea.Msg {
-		ctx := context.Background()
-		opts := brew.UninstallOptions{Cask: pkg.Type == brew.TypeCask}
-		err := v.client.Uninstall(ctx, pkg.Name, opts)
-		if err != nil {
-			return ErrorMsgView{Err: err}
+// scrollPanel scrolls a panel's list without moving its selection
+func (v *DashboardView) scrollPanel(panel PanelType, delta int) {
+	clamp := func(scroll, total, visible int) int {
+		if scroll > total-visible {
+			scroll = total - visible
 		}
-		return SuccessMsgView{Msg: "Uninstalled " + pkg.Name}
+		if scroll < 0 {
+			scroll = 0
+		}
+		return scroll
+	}
+
+	switch panel {
+	case PanelInstalled:
+		v.installedScroll = clamp(v.installedScroll+delta, len(v.state.GetFilteredPackages()), v.getInstalledVisibleLines())
+	case PanelSearch:
+		v.searchScroll = clamp(v.searchScroll+delta, len(v.searchResults), v.getSearchVisibleLines())
+	case PanelDependencies:
+		if v.packageInfo != nil {
+			v.depScroll = clamp(v.depScroll+delta, len(v.packageInfo.Dependencies), v.getDependenciesVisibleLines())
+		}
+	case PanelLogs:
+		// The logs scroll up towards older entries
+		v.logs.scrollBy(-delta, v.getLogsVisibleLines())
 	}
 }
 
-func (v *DashboardView) upgradePackage(name string) tea.Cmd {
-	v.operationInProgress = true
-	v.operationMessage = fmt.Sprintf("Upgrading %s...", name)
-	v.addLog(fmt.Sprintf("→ Upgrading %s...", name))
-	return func() tea.Msg {
-		ctx := context.Background()
-		err := v.client.Upgrade(ctx, []string{name})
-		if err != nil {
-			return ErrorMsgView{Err: err}
+// clickPanel focuses a panel and selects the item on the clicked row, which
+// is negative for clicks above the list
+func (v *DashboardView) clickPanel(panel PanelType, row int) tea.Cmd {
+	v.searchInput.Blur()
+	v.focusedPanel = panel
+
+	switch panel {
+	case PanelInstalled:
+		i := v.installedScroll + row
+		if row >= 0 && row < v.getInstalledVisibleLines() && i < len(v.state.GetFilteredPackages()) {
+			v.installedIndex = i
+			v.updateSelectedPackage()
+			return v.loadSelectedPackageInfo()
+		}
+		v.updateSelectedPackage()
+
+	case PanelSearch:
+		i := v.searchScroll + row
+		if len(v.searchResults) > 0 && row >= 0 && row < v.getSearchVisibleLines() && i < len(v.searchResults) {
+			v.searchIndex = i
+			v.updateSelectedPackage()
+			return v.loadSelectedPackageInfo()
 		}
-		return SuccessMsgView{Msg: "Upgraded " + name}
+		// Anywhere else in the panel starts typing a search, like Tab does
+		return v.searchInput.Focus()
+
+	case PanelDependencies:
+		if v.packageInfo == nil {
+			return nil
+		}
+		i := v.depScroll + row
+		if row < 0 || row >= v.getDependenciesVisibleLines() || i >= len(v.packageInfo.Dependencies) {
+			return nil
+		}
+		return v.selectInstalled(v.packageInfo.Dependencies[i])
 	}
+	return nil
 }
 
-func (v *DashboardView) upgradeAll() tea.Cmd {
-	v.operationInProgress = true
-	v.operationMessage = "Upgrading all packages..."
-	v.addLog("→ Upgrading all packages...")
+// selectInstalled focuses the installed list on a package, if it is listed
+func (v *DashboardView) selectInstalled(name string) tea.Cmd {
+	for _, pkg := range v.state.GetFilteredPackages() {
+		if pkg.Name == name {
+			v.focusedPanel = PanelInstalled
+			v.restoreSelection(name)
+			return v.loadSelectedPackageInfo()
+		}
+	}
+	v.addLog(fmt.Sprintf("%s is not in the installed list", name))
+	return nil
+}
+
+// exportLogs writes the session log to a file in the working directory
+func (v *DashboardView) exportLogs() tea.Cmd {
+	dir, err := os.Getwd()
+	if err != nil {
+		v.addLog("Error: " + err.Error())
+		return nil
+	}
+	path, err := v.logs.export(dir)
+	if err != nil {
+		v.addLog("Error: failed to export logs: " + err.Error())
+		return nil
+	}
 	return func() tea.Msg {
-		ctx := context.Background()
-		err := v.client.Upgrade(ctx, []string{})
+		return ExportedMsg{What: "session log", Path: path}
+	}
+}
+
+// exportSBOM writes an SBOM of the installed packages to the working directory
+func (v *DashboardView) exportSBOM() tea.Cmd {
+	format, err := sbom.ParseFormat(v.config.SBOMFormat)
+	if err != nil {
+		format = sbom.FormatCycloneDX
+	}
+	v.logs.begin("export SBOM")
+	v.addLog(fmt.Sprintf("→ Exporting %s SBOM...", format))
+	packages := v.state.GetInstalledPackages()
+	return func() tea.Msg {
+		dir, err := os.Getwd()
 		if err != nil {
 			return ErrorMsgView{Err: err}
 		}
-		return SuccessMsgView{Msg: "Upgraded all packages"}
+		path, err := sbom.WriteFile(dir, sbom.NewDocument(packages), format)
+		if err != nil {
+			return ErrorMsgView{Err: fmt.Errorf("failed to export SBOM: %w", err)}
+		}
+		return ExportedMsg{What: "SBOM", Path: path}
 	}
 }
 
@@ -997,71 +1526,208 @@ func (v *DashboardView) refresh() tea.Cmd {
 func (v *DashboardView) runDoctor() tea.Cmd {
 	v.operationInProgress = true
 	v.operationMessage = "Running brew doctor..."
+	v.logs.begin("doctor")
 	v.addLog("→ Running brew doctor...")
 	return func() tea.Msg {
 		ctx := context.Background()
-		output, err := v.client.Doctor(ctx)
+		diagnostics, err := v.client.Diagnose(ctx)
 		if err != nil {
 			return ErrorMsgView{Err: err}
 		}
-		// Add output to logs (split by lines)
-		lines := strings.Split(strings.TrimSpace(output), "\n")
-		return DoctorOutputMsg{Lines: lines}
+		return DoctorOutputMsg{Diagnostics: diagnostics}
 	}
 }
 
-func (v *DashboardView) runCleanup() tea.Cmd {
+func (v *DashboardView) previewCleanup() tea.Cmd {
 	v.operationInProgress = true
-	v.operationMessage = "Running brew cleanup..."
-	v.addLog("→ Running brew cleanup...")
+	v.operationMessage = "Previewing brew cleanup..."
+	v.logs.begin("cleanup")
+	v.addLog("→ Running brew cleanup --dry-run...")
 	return func() tea.Msg {
 		ctx := context.Background()
-		err := v.client.Cleanup(ctx)
+		items, err := v.client.CleanupDryRun(ctx)
 		if err != nil {
 			return ErrorMsgView{Err: err}
 		}
-		return SuccessMsgView{Msg: "Cleanup completed"}
+		return CleanupPreviewMsg{Action: "cleanup", Items: items}
 	}
 }
 
-func (v *DashboardView) runAutoremove() tea.Cmd {
+func (v *DashboardView) previewAutoremove() tea.Cmd {
 	v.operationInProgress = true
-	v.operationMessage = "Running brew autoremove..."
-	v.addLog("→ Running brew autoremove...")
+	v.operationMessage = "Previewing brew autoremove..."
+	v.logs.begin("autoremove")
+	v.addLog("→ Running brew autoremove --dry-run...")
 	return func() tea.Msg {
 		ctx := context.Background()
-		err := v.client.Autoremove(ctx)
+		items, err := v.client.AutoremoveDryRun(ctx)
 		if err != nil {
 			return ErrorMsgView{Err: err}
 		}
-		return SuccessMsgView{Msg: "Autoremove completed"}
+		// Autoremove doesn't report sizes, use the computed disk usage
+		for i := range items {
+			if pkg, ok := v.state.GetInstalledPackage(items[i].Name); ok {
+				items[i].Size = pkg.Size
+			}
+		}
+		return CleanupPreviewMsg{Action: "autoremove", Items: items}
+	}
+}
+
+// showPreview opens the checklist for a dry-run result. Cleanup items are
+// grouped by package, as brew cleanup only takes package names.
+func (v *DashboardView) showPreview(action string, items []brew.CleanupItem) tea.Cmd {
+	other := 0
+	if action == "cleanup" {
+		items, other = groupCleanupItems(items)
+		if other > 0 {
+			v.addLog(fmt.Sprintf("%d logs and caches not tied to a package are left for a full brew cleanup", other))
+		}
+	}
+	if len(items) == 0 {
+		if action == "autoremove" {
+			v.addLog("✓ No unused dependencies to remove")
+		} else {
+			v.addLog("✓ Nothing to clean up")
+		}
+		return nil
+	}
+
+	var total int64
+	checklistItems := make([]components.ChecklistItem, len(items))
+	for i, item := range items {
+		total += item.Size
+		label := item.Path
+		if label == "" {
+			label = item.Name
+		}
+		detail := ""
+		if item.Size > 0 {
+			detail = brew.FormatSize(item.Size)
+		}
+		checklistItems[i] = components.ChecklistItem{Label: label, Detail: detail}
+	}
+
+	message := fmt.Sprintf("brew %s would remove %d items", action, len(items))
+	if total > 0 {
+		message += fmt.Sprintf(", freeing about %s", brew.FormatSize(total))
+	}
+	v.addLog(message)
+
+	v.pendingAction = action
+	v.previewItems = items
+	v.checklist.SetTitle("Preview: brew " + action)
+	v.checklist.Show(message, checklistItems)
+	return nil
+}
+
+// runApproved asks the app to run cleanup or autoremove on the items left
+// checked
+func (v *DashboardView) runApproved(action string, checked []components.ChecklistItem) tea.Cmd {
+	approved := make(map[string]bool, len(checked))
+	for _, item := range checked {
+		approved[item.Label] = true
+	}
+
+	var selected []brew.CleanupItem
+	for _, item := range v.previewItems {
+		label := item.Path
+		if label == "" {
+			label = item.Name
+		}
+		if approved[label] {
+			selected = append(selected, item)
+		}
 	}
+	all := len(selected) == len(v.previewItems)
+	v.previewItems = nil
+
+	names := make([]string, len(selected))
+	for i, item := range selected {
+		names[i] = item.Name
+	}
+	switch action {
+	case "cleanup":
+		return func() tea.Msg { return RequestCleanupMsg{Packages: names} }
+	case "autoremove":
+		return func() tea.Msg { return RequestAutoremoveMsg{Packages: names, All: all} }
+	}
+	return nil
+}
+
+// groupCleanupItems sums cleanup items per package, counting the items that
+// belong to no package
+func groupCleanupItems(items []brew.CleanupItem) ([]brew.CleanupItem, int) {
+	var groups []brew.CleanupItem
+	index := make(map[string]int)
+	other := 0
+	for _, item := range items {
+		if item.Name == "" {
+			other++
+			continue
+		}
+		i, ok := index[item.Name]
+		if !ok {
+			i = len(groups)
+			index[item.Name] = i
+			groups = append(groups, brew.CleanupItem{Name: item.Name})
+		}
+		groups[i].Size += item.Size
+	}
+	return groups, other
+}
+
+// keepSelectionVisible scrolls the lists after a resize so their selected
+// rows are still shown
+func (v *DashboardView) keepSelectionVisible() {
+	follow := func(index, scroll, visible int) int {
+		if index < scroll {
+			return index
+		}
+		if index >= scroll+visible {
+			return index - visible + 1
+		}
+		return scroll
+	}
+	v.installedScroll = follow(v.installedIndex, v.installedScroll, v.getInstalledVisibleLines())
+	v.searchScroll = follow(v.searchIndex, v.searchScroll, v.getSearchVisibleLines())
+	if v.packageInfo != nil {
+		maxScroll := max(len(v.packageInfo.Dependencies)-v.getDependenciesVisibleLines(), 0)
+		v.depScroll = min(v.depScroll, maxScroll)
+	}
+}
+
+// visibleRows returns how many list rows fit in a panel next to its other lines
+func (v *DashboardView) visibleRows(panel PanelType, otherLines int) int {
+	_, height := inner(v.layout().rect(panel))
+	return max(height-otherLines, 1)
 }
 
 func (v *DashboardView) getInstalledVisibleLines() int {
-	contentHeight := v.height - 1
-	installedHeight := contentHeight
-	return installedHeight - 5
+	other := 3 // title, header, separator
+	if v.filterInput.Focused() {
+		other++
+	}
+	if total, _ := v.state.GetDiskUsage(); total > 0 {
+		other += 2 // disk usage summary
+	}
+	return v.visibleRows(PanelInstalled, other)
 }
 
 func (v *DashboardView) getSearchVisibleLines() int {
-	contentHeight := v.height - 1
-	searchHeight := int(float64(contentHeight) * 0.35)
-	maxLines := searchHeight - 7
-	if maxLines < 2 {
-		maxLines = 2
-	}
-	return maxLines
+	return v.visibleRows(PanelSearch, 4) // title, input, result count, more
 }
 
 func (v *DashboardView) getDependenciesVisibleLines() int {
-	contentHeight := v.height - 1
-	depTreeHeight := int(float64(contentHeight) * 0.35)
-	maxLines := depTreeHeight - 6
-	if maxLines < 1 {
-		maxLines = 1
+	return v.visibleRows(PanelDependencies, 4) // title, package name, more, above
+}
+
+func (v *DashboardView) getLogsVisibleLines() int {
+	other := 1 // title
+	if v.logs.search.Focused() {
+		other++
 	}
-	return maxLines
+	return v.visibleRows(PanelLogs, other)
 }
 
 // PackageListItem for installed list
@@ -1081,9 +1747,22 @@ func (i PackageListItem) Description() string { return "" }
 
 // Message types
 type PackagesLoadedMsg struct{ Packages []brew.Package }
-type OutdatedLoadedMsg struct{ Packages []brew.OutdatedPackage }
+type OutdatedLoadedMsg struct {
+	Packages   []brew.OutdatedPackage
+	Background bool // From a background update check
+}
+type DiskUsageLoadedMsg struct{ Usage []brew.DiskUsage }
 type DebouncedLoadMsg struct {
 	pkg *brew.Package
 	id  int
 }
-type DoctorOutputMsg struct{ Lines []string }
+type ExportedMsg struct {
+	What string
+	Path string
+}
+type DoctorOutputMsg struct{ Diagnostics []brew.Diagnostic }
+
+type CleanupPreviewMsg struct {
+	Action string // "cleanup" or "autoremove"
+	Items  []brew.CleanupItem
+}
@@ -0,0 +1,226 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// DebugView lists the brew commands recorded in debug mode, newest first
+type DebugView struct {
+	invocations []brew.Invocation
+	cursor      int
+	scroll      int
+	expanded    bool // Show the selected command's environment and output
+
+	width  int
+	height int
+}
+
+// NewDebugView creates a new debug view
+func NewDebugView() *DebugView {
+	return &DebugView{}
+}
+
+// SetSize sets the view size
+func (v *DebugView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Init initializes the view
+func (v *DebugView) Init() tea.Cmd {
+	v.reload()
+	v.cursor = 0
+	v.scroll = 0
+	return nil
+}
+
+// Update handles messages
+func (v *DebugView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.keepCursorVisible()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.cursor < len(v.invocations)-1 {
+				v.cursor++
+			}
+			v.keepCursorVisible()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			v.expanded = !v.expanded
+			v.keepCursorVisible()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+			v.reload()
+		}
+	}
+	return v, nil
+}
+
+// reload takes the recorded commands, keeping the cursor on the same one
+func (v *DebugView) reload() {
+	selected := -1
+	if v.cursor < len(v.invocations) {
+		selected = v.invocations[v.cursor].ID
+	}
+
+	traces := brew.Traces()
+	v.invocations = make([]brew.Invocation, len(traces))
+	for i, inv := range traces {
+		v.invocations[len(traces)-1-i] = inv
+	}
+
+	v.cursor = 0
+	for i, inv := range v.invocations {
+		if inv.ID == selected {
+			v.cursor = i
+		}
+	}
+	v.keepCursorVisible()
+}
+
+// View renders the view
+func (v *DebugView) View() string {
+	sections := []string{styles.TitleStyle.Render("Debug"), ""}
+
+	path, tracing := brew.Tracing()
+	switch {
+	case !tracing:
+		sections = append(sections,
+			styles.DimStyle.Render("Debug tracing is off."),
+			styles.DimStyle.Render(`Start brewst with --debug, or set "debug": true in ~/.config/brewst/config.json, to record every brew command.`))
+	case len(v.invocations) == 0:
+		sections = append(sections,
+			styles.DimStyle.Render("Tracing to "+path),
+			"",
+			styles.DimStyle.Render("No brew commands recorded yet"))
+	default:
+		sections = append(sections,
+			styles.DimStyle.Render(fmt.Sprintf("Tracing to %s • %d recent commands", path, len(v.invocations))),
+			"")
+		sections = append(sections, v.renderList()...)
+		if v.expanded {
+			sections = append(sections, "")
+			sections = append(sections, v.renderDetails()...)
+		}
+	}
+
+	sections = append(sections, "", styles.HelpStyle.Render("↑/↓: Navigate | Enter: Details | r: Reload | Esc: Back"))
+	return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (v *DebugView) renderList() []string {
+	header := fmt.Sprintf("  %-5s %-8s %9s %4s  %s", "#", "STARTED", "DURATION", "EXIT", "COMMAND")
+	lines := []string{styles.DimStyle.Render(header)}
+
+	end := v.scroll + v.visibleRows()
+	if end > len(v.invocations) {
+		end = len(v.invocations)
+	}
+	for i := v.scroll; i < end; i++ {
+		inv := v.invocations[i]
+		exit := styles.SuccessMessageStyle.Render(fmt.Sprintf("%4d", inv.ExitCode))
+		if inv.ExitCode != 0 {
+			exit = styles.ErrorStyle.Render(fmt.Sprintf("%4d", inv.ExitCode))
+		}
+		line := fmt.Sprintf("%-5d %-8s %9s %s  %s",
+			inv.ID,
+			inv.Start.Format("15:04:05"),
+			formatDuration(inv.Duration()),
+			exit,
+			truncate(inv.Command(), v.width-40))
+		if i == v.cursor {
+			line = "▶ " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	if end < len(v.invocations) {
+		lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(v.invocations)-end)))
+	}
+	return lines
+}
+
+// renderDetails shows everything recorded for the selected command
+func (v *DebugView) renderDetails() []string {
+	if v.cursor >= len(v.invocations) {
+		return nil
+	}
+	inv := v.invocations[v.cursor]
+
+	env := "(none)"
+	if len(inv.Env) > 0 {
+		env = strings.Join(inv.Env, " ")
+	}
+	lines := []string{
+		styles.KeyStyle.Render("Command: ") + styles.ValueStyle.Render(inv.Command()),
+		styles.KeyStyle.Render("Dir:     ") + styles.ValueStyle.Render(inv.Dir),
+		styles.KeyStyle.Render("Env:     ") + styles.ValueStyle.Render(env),
+		styles.KeyStyle.Render("Time:    ") + styles.ValueStyle.Render(fmt.Sprintf("%s – %s (%s)",
+			inv.Start.Format("15:04:05.000"), inv.End.Format("15:04:05.000"), formatDuration(inv.Duration()))),
+		styles.KeyStyle.Render("Exit:    ") + styles.ValueStyle.Render(fmt.Sprint(inv.ExitCode)),
+	}
+	if inv.Err != "" {
+		lines = append(lines, styles.KeyStyle.Render("Error:   ")+styles.ErrorStyle.Render(inv.Err))
+	}
+
+	// Output gets whatever room the list leaves
+	room := v.height - len(lines) - v.visibleRows() - 12
+	for _, output := range []struct{ name, text string }{{"stdout", inv.Stdout}, {"stderr", inv.Stderr}} {
+		if output.text == "" || room < 2 {
+			continue
+		}
+		lines = append(lines, styles.KeyStyle.Render(output.name+":"))
+		outputLines := strings.Split(strings.TrimRight(output.text, "\n"), "\n")
+		shown := outputLines
+		if len(shown) > room-1 {
+			shown = shown[:room-1]
+		}
+		for _, line := range shown {
+			lines = append(lines, styles.DimStyle.Render("  "+truncate(line, v.width-8)))
+		}
+		if len(shown) < len(outputLines) {
+			lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("  ... %d more lines in the trace file", len(outputLines)-len(shown))))
+		}
+		room -= len(shown) + 1
+	}
+	return lines
+}
+
+func (v *DebugView) visibleRows() int {
+	rows := v.height - 12
+	if v.expanded {
+		rows = (v.height - 12) / 3
+	}
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+func (v *DebugView) keepCursorVisible() {
+	if v.cursor < v.scroll {
+		v.scroll = v.cursor
+	}
+	if v.cursor >= v.scroll+v.visibleRows() {
+		v.scroll = v.cursor - v.visibleRows() + 1
+	}
+}
+
+// formatDuration rounds a duration for display
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
@@ -9,26 +9,35 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/license"
 	"github.com/lazar0169/brewst/internal/state"
 	"github.com/lazar0169/brewst/internal/ui/styles"
 )
 
-// DetailsView shows package details
+// DetailsView shows everything brew knows about the selected package
 type DetailsView struct {
 	client brew.Client
 	state  *state.State
+	config *state.Config
 
 	packageInfo *brew.PackageInfo
 	loading     bool
+	err         error
+	scroll      int
 	width       int
 	height      int
+
+	// Versions to choose the linked one from, nil when not choosing
+	versions []string
+	version  int
 }
 
 // NewDetailsView creates a new package details view
-func NewDetailsView(client brew.Client, state *state.State) *DetailsView {
+func NewDetailsView(client brew.Client, state *state.State, config *state.Config) *DetailsView {
 	return &DetailsView{
 		client:  client,
 		state:   state,
+		config:  config,
 		loading: false,
 	}
 }
@@ -37,10 +46,21 @@ func NewDetailsView(client brew.Client, state *state.State) *DetailsView {
 func (v *DetailsView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
+	// The details wrap to the width, so there may be fewer lines now
+	v.scrollBy(0)
+}
+
+// CapturingInput reports whether the version picker is taking the keys
+func (v *DetailsView) CapturingInput() bool {
+	return v.versions != nil
 }
 
 // Init initializes the view
 func (v *DetailsView) Init() tea.Cmd {
+	v.packageInfo = nil
+	v.err = nil
+	v.scroll = 0
+	v.versions = nil
 	if v.state.SelectedPackage != nil {
 		return v.loadPackageInfo(v.state.SelectedPackage)
 	}
@@ -51,7 +71,42 @@ func (v *DetailsView) Init() tea.Cmd {
 func (v *DetailsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.versions != nil {
+			return v, v.handleVersionKey(msg)
+		}
 		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			v.scrollBy(-1)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			v.scrollBy(1)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgup"))):
+			v.scrollBy(-v.visibleLines())
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgdown", " "))):
+			v.scrollBy(v.visibleLines())
+		case key.Matches(msg, key.NewBinding(key.WithKeys("g", "home"))):
+			v.scroll = 0
+		case key.Matches(msg, key.NewBinding(key.WithKeys("G", "end"))):
+			v.scrollBy(len(v.lines()))
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+			if v.state.SelectedPackage != nil {
+				v.err = nil
+				return v, v.loadPackageInfo(v.state.SelectedPackage)
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("l"))):
+			if v.canSwitchVersion() {
+				v.versions = v.packageInfo.InstalledVersions
+				v.version = 0
+				for i, version := range v.versions {
+					if version == v.packageInfo.LinkedVersion {
+						v.version = i
+					}
+				}
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("R"))):
+			if selected := v.state.SelectedPackage; selected != nil && selected.Installed {
+				pkg := *selected
+				return v, func() tea.Msg { return RequestReinstallMsg{Package: pkg} }
+			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("i"))):
 			// Install/Uninstall
 			if v.state.SelectedPackage != nil {
@@ -68,11 +123,32 @@ func (v *DetailsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case PackageInfoLoadedMsg:
+		// The dashboard may still be loading info for its own selection
+		selected := v.state.SelectedPackage
+		if msg.Info == nil || selected == nil || msg.Info.Name != selected.Name {
+			return v, nil
+		}
+		// Reloads keep the scroll position, another package starts at the top
+		if v.packageInfo == nil || v.packageInfo.Name != msg.Info.Name || v.packageInfo.Type != msg.Info.Type {
+			v.scroll = 0
+		}
 		v.packageInfo = msg.Info
 		v.loading = false
+		v.scrollBy(0)
+		// Keep the install and uninstall action in step with the package
+		pkg := *selected
+		pkg.Installed, pkg.Pinned = msg.Info.Installed, msg.Info.Pinned
+		v.state.SetSelectedPackage(&pkg)
+		return v, nil
+
+	case ActionFinishedMsg:
+		if msg.Err == nil && v.state.SelectedPackage != nil {
+			return v, v.loadPackageInfo(v.state.SelectedPackage)
+		}
 		return v, nil
 
 	case ErrorMsgView:
+		v.err = msg.Err
 		v.loading = false
 		return v, nil
 	}
@@ -82,107 +158,244 @@ func (v *DetailsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the view
 func (v *DetailsView) View() string {
-	if v.loading {
-		return styles.AppStyle.Render("Loading package details...")
+	name := "Package Details"
+	if v.state.SelectedPackage != nil {
+		name = v.state.SelectedPackage.Name
+	}
+	sections := []string{styles.TitleStyle.Render(name)}
+
+	switch {
+	case v.state.SelectedPackage == nil:
+		sections = append(sections, "", styles.DimStyle.Render("No package selected"))
+	case v.err != nil:
+		sections = append(sections, "", styles.ErrorStyle.Render("Error: "+v.err.Error()))
+	case v.loading || v.packageInfo == nil:
+		sections = append(sections, "", styles.DimStyle.Render("Loading package details..."))
+	case v.versions != nil:
+		sections = append(sections, "", styles.KeyStyle.Render("Link which version?"), "")
+		for i, version := range v.versions {
+			row := "  " + styles.ValueStyle.Render(version)
+			if i == v.version {
+				row = styles.SelectedStyle.Render("▶ " + version)
+			}
+			if version == v.packageInfo.LinkedVersion {
+				row += styles.DimStyle.Render(" (linked)")
+			}
+			sections = append(sections, row)
+		}
+		help := "↑/↓: Select | Enter: Link | Esc: Cancel"
+		sections = append(sections, "", styles.HelpStyle.Render(help))
+		return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+	default:
+		lines := v.lines()
+		start := min(v.scroll, max(len(lines)-v.visibleLines(), 0))
+		end := min(start+v.visibleLines(), len(lines))
+		sections = append(sections, lines[start:end]...)
+		if end < len(lines) {
+			sections = append(sections, styles.DimStyle.Render(fmt.Sprintf("↓ %d more lines", len(lines)-end)))
+		}
 	}
 
+	action := "Install"
+	if v.state.SelectedPackage != nil && v.state.SelectedPackage.Installed {
+		action = "Uninstall | R: Reinstall"
+	}
+	help := fmt.Sprintf("↑/↓: Scroll | i: %s | r: Reload | Esc: Back", action)
+	if v.canSwitchVersion() {
+		help = fmt.Sprintf("↑/↓: Scroll | i: %s | l: Switch version | r: Reload | Esc: Back", action)
+	}
+	sections = append(sections, "", styles.HelpStyle.Render(help))
+
+	return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// lines renders the loaded details at the view's width
+func (v *DetailsView) lines() []string {
 	if v.packageInfo == nil {
-		return styles.AppStyle.Render("No package selected")
+		return nil
 	}
+	return packageDetails(v.packageInfo, v.config.LicensePolicy, v.width)
+}
 
+// visibleLines is how many detail lines fit between the title and the help
+func (v *DetailsView) visibleLines() int {
+	return max(v.height-4, 3)
+}
+
+func (v *DetailsView) scrollBy(delta int) {
+	maxScroll := max(len(v.lines())-v.visibleLines(), 0)
+	v.scroll = min(max(v.scroll+delta, 0), maxScroll)
+}
+
+// canSwitchVersion reports whether the package is a formula with several
+// versions in the Cellar to choose the linked one from
+func (v *DetailsView) canSwitchVersion() bool {
 	info := v.packageInfo
+	return info != nil && info.Type == brew.TypeFormula && len(info.InstalledVersions) > 1
+}
 
-	// Package header
-	header := styles.TitleStyle.Render(info.Name)
-	if info.Version != "" {
-		version := styles.DimStyle.Render(fmt.Sprintf("Version: %s", info.Version))
-		header = lipgloss.JoinVertical(lipgloss.Left, header, version)
+// handleVersionKey handles keys while choosing the version to link
+func (v *DetailsView) handleVersionKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		v.version = max(v.version-1, 0)
+	case "down", "j":
+		v.version = min(v.version+1, len(v.versions)-1)
+	case "esc":
+		v.versions = nil
+	case "enter":
+		version := v.versions[v.version]
+		v.versions = nil
+		if version == v.packageInfo.LinkedVersion {
+			return nil
+		}
+		pkg := v.packageInfo.Package
+		return func() tea.Msg { return RequestLinkMsg{Package: pkg, Version: version} }
 	}
+	return nil
+}
 
-	// Type
-	pkgType := "Formula"
-	if info.Type == brew.TypeCask {
-		pkgType = "Cask"
+func (v *DetailsView) loadPackageInfo(pkg *brew.Package) tea.Cmd {
+	v.loading = true
+	return func() tea.Msg {
+		ctx := context.Background()
+		info, err := v.client.Info(ctx, pkg.Name, pkg.Type == brew.TypeCask)
+		if err != nil {
+			return ErrorMsgView{Err: err}
+		}
+		return PackageInfoLoadedMsg{Info: info}
 	}
-	typeStr := fmt.Sprintf("%s %s", styles.KeyStyle.Render("Type:"), pkgType)
+}
 
-	// Description
-	desc := ""
-	if info.Description != "" {
-		desc = fmt.Sprintf("%s %s", styles.KeyStyle.Render("Description:"), info.Description)
-	}
+// detailsKeyWidth lines up the values after the field names
+const detailsKeyWidth = 14
 
-	// Homepage
-	homepage := ""
-	if info.Homepage != "" {
-		homepage = fmt.Sprintf("%s %s", styles.KeyStyle.Render("Homepage:"), info.Homepage)
+// packageDetails renders a package's details as lines no wider than width.
+// Long values and caveats wrap, and fields brew doesn't report are left out.
+func packageDetails(info *brew.PackageInfo, policy license.Policy, width int) []string {
+	var lines []string
+	field := func(name, value string, style lipgloss.Style) {
+		if value == "" {
+			return
+		}
+		key := styles.KeyStyle.Render(fmt.Sprintf("%-*s", detailsKeyWidth, name+":"))
+		for i, line := range wrapText(value, width-detailsKeyWidth) {
+			if i > 0 {
+				key = strings.Repeat(" ", detailsKeyWidth)
+			}
+			lines = append(lines, key+style.Render(line))
+		}
 	}
-
-	// Dependencies
-	deps := ""
-	if len(info.Dependencies) > 0 {
-		depsTitle := styles.KeyStyle.Render("Dependencies:")
-		depsList := strings.Join(info.Dependencies, ", ")
-		deps = fmt.Sprintf("%s %s", depsTitle, depsList)
+	section := func(name string) {
+		lines = append(lines, "", styles.KeyStyle.Render(name))
 	}
 
-	// Build dependencies
-	buildDeps := ""
-	if len(info.BuildDeps) > 0 {
-		buildDepsTitle := styles.KeyStyle.Render("Build Dependencies:")
-		buildDepsList := strings.Join(info.BuildDeps, ", ")
-		buildDeps = fmt.Sprintf("%s %s", buildDepsTitle, buildDepsList)
+	pkgType := "Formula"
+	if info.Type == brew.TypeCask {
+		pkgType = "Cask"
 	}
+	lines = append(lines, "")
+	field("Description", info.Description, styles.ValueStyle)
+	field("Type", pkgType, styles.ValueStyle)
+	field("Version", info.Version, styles.ValueStyle)
+	field("Tap", info.Tap, styles.ValueStyle)
+	field("Homepage", info.Homepage, styles.DimStyle)
 
-	// Caveats
-	caveats := ""
-	if info.Caveats != "" {
-		caveatsTitle := styles.KeyStyle.Render("Caveats:")
-		caveats = lipgloss.JoinVertical(lipgloss.Left, caveatsTitle, info.Caveats)
+	// License, red when the license policy disallows it
+	if info.License != "" {
+		if policy.Allows(info.License) {
+			field("License", license.Normalize(info.License), styles.ValueStyle)
+		} else {
+			field("License", license.Normalize(info.License)+" (disallowed)", styles.ErrorStyle)
+		}
 	}
 
-	// Assemble content
-	var sections []string
-	sections = append(sections, header, "", typeStr)
-	if desc != "" {
-		sections = append(sections, desc)
-	}
-	if homepage != "" {
-		sections = append(sections, homepage)
+	var status []string
+	if info.Deprecated {
+		status = append(status, "deprecated")
 	}
-	if deps != "" {
-		sections = append(sections, "", deps)
+	if info.Outdated {
+		status = append(status, "outdated")
 	}
-	if buildDeps != "" {
-		sections = append(sections, buildDeps)
+	if info.Pinned {
+		status = append(status, "pinned")
 	}
-	if caveats != "" {
-		sections = append(sections, "", caveats)
+	field("Status", strings.Join(status, ", "), styles.OutdatedStyle)
+
+	section("Installation")
+	if len(info.InstalledVersions) == 0 {
+		lines = append(lines, styles.DimStyle.Render("Not installed"))
+	} else {
+		installed := make([]string, len(info.InstalledVersions))
+		for i, version := range info.InstalledVersions {
+			installed[i] = version
+			if version == info.LinkedVersion && len(info.InstalledVersions) > 1 {
+				installed[i] += " (linked)"
+			}
+		}
+		field("Installed", strings.Join(installed, ", "), styles.InstalledStyle)
+		if info.Type == brew.TypeFormula && info.LinkedVersion == "" {
+			field("Linked", "not linked", styles.OutdatedStyle)
+		}
+		if !info.InstallDate.IsZero() {
+			field("Installed on", info.InstallDate.Format("2006-01-02 15:04"), styles.ValueStyle)
+		}
+		// Casks are always installed on request and never built
+		if info.Type == brew.TypeFormula {
+			reason := "as a dependency"
+			if info.OnRequest {
+				reason = "on request"
+			}
+			field("Reason", reason, styles.ValueStyle)
+			build := "built from source"
+			if info.FromBottle {
+				build = "poured from bottle"
+			}
+			field("Build", build, styles.ValueStyle)
+		}
+		if info.Size > 0 {
+			field("Size", brew.FormatSize(info.Size), styles.ValueStyle)
+		}
 	}
 
-	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	if len(info.Dependencies) > 0 || len(info.BuildDeps) > 0 || len(info.Conflicts) > 0 {
+		section("Relationships")
+		field("Dependencies", strings.Join(info.Dependencies, ", "), styles.ValueStyle)
+		field("Build deps", strings.Join(info.BuildDeps, ", "), styles.ValueStyle)
+		field("Conflicts", strings.Join(info.Conflicts, ", "), styles.ErrorStyle)
+	}
 
-	// Help text
-	helpText := "i: Install/Uninstall | Esc: Back"
-	help := styles.HelpStyle.Render(helpText)
+	if len(info.Options) > 0 {
+		section("Options")
+		for _, option := range info.Options {
+			lines = append(lines, styles.ValueStyle.Render(option.Flag))
+			for _, line := range wrapText(option.Description, width-4) {
+				lines = append(lines, "    "+styles.DimStyle.Render(line))
+			}
+		}
+	}
 
-	return styles.AppStyle.Render(content) + "\n" + help
+	if caveats := strings.TrimSpace(info.Caveats); caveats != "" {
+		section("Caveats")
+		for _, line := range wrapText(caveats, width) {
+			lines = append(lines, styles.OutdatedStyle.Render(line))
+		}
+	}
+	return lines
 }
 
-func (v *DetailsView) loadPackageInfo(pkg *brew.Package) tea.Cmd {
-	v.loading = true
-	return func() tea.Msg {
-		ctx := context.Background()
-		info, err := v.client.Info(ctx, pkg.Name, pkg.Type == brew.TypeCask)
-		if err != nil {
-			return ErrorMsgView{Err: err}
-		}
-		return PackageInfoLoadedMsg{Info: info}
+// wrapText wraps s to width cells, keeping its own line breaks
+func wrapText(s string, width int) []string {
+	if width < 10 {
+		width = 10
 	}
+	wrapped := lipgloss.NewStyle().Width(width).Render(s)
+	lines := strings.Split(wrapped, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return lines
 }
 
 // Message types
-type (
-	PackageInfoLoadedMsg struct{ Info *brew.PackageInfo }
-	RequestInstallMsg    struct{ Package brew.Package }
-)
+type PackageInfoLoadedMsg struct{ Info *brew.PackageInfo }
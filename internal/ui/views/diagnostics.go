@@ -2,36 +2,48 @@ package views
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lazar0169/brewst/internal/brew"
 	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/components"
 	"github.com/lazar0169/brewst/internal/ui/styles"
 )
 
-// DiagnosticsView shows brew doctor output
+// DiagnosticsView shows brew doctor warnings with their suggested fixes
 type DiagnosticsView struct {
 	client brew.Client
 	state  *state.State
 
-	viewport viewport.Model
-	output   string
-	loading  bool
-	width    int
-	height   int
+	diagnostics  []brew.Diagnostic
+	selected     int  // Selected diagnostic
+	command      int  // Selected suggested command
+	focusOnFixes bool // Whether the command list has focus
+	loading      bool
+	running      bool
+	result       string // Output of the last command run
+
+	dialog         *components.Dialog
+	pendingCommand string
+	requested      bool   // The app was asked to run pendingCommand
+	runningOp      string // Action the command runs as, to collect its output
+
+	width  int
+	height int
 }
 
 // NewDiagnosticsView creates a new diagnostics view
 func NewDiagnosticsView(client brew.Client, state *state.State) *DiagnosticsView {
-	vp := viewport.New(80, 20)
 	return &DiagnosticsView{
-		client:   client,
-		state:    state,
-		viewport: vp,
-		loading:  false,
+		client:  client,
+		state:   state,
+		dialog:  components.NewConfirmDialog("Run suggested command", ""),
+		loading: false,
 	}
 }
 
@@ -39,8 +51,11 @@ func NewDiagnosticsView(client brew.Client, state *state.State) *DiagnosticsView
 func (v *DiagnosticsView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
-	v.viewport.Width = width - 4
-	v.viewport.Height = height - 8
+}
+
+// CapturingInput reports whether the confirmation dialog owns the keyboard
+func (v *DiagnosticsView) CapturingInput() bool {
+	return v.dialog.IsVisible()
 }
 
 // Init initializes the view
@@ -50,7 +65,11 @@ func (v *DiagnosticsView) Init() tea.Cmd {
 
 // Update handles messages
 func (v *DiagnosticsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
+	if v.dialog.IsVisible() {
+		var cmd tea.Cmd
+		v.dialog, cmd = v.dialog.Update(msg)
+		return v, cmd
+	}
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -58,29 +77,113 @@ func (v *DiagnosticsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
 			// Refresh diagnostics
 			return v, v.runDiagnostics()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
+			if d := v.current(); d != nil && len(d.Commands) > 0 {
+				v.focusOnFixes = !v.focusOnFixes
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.focusOnFixes {
+				if v.command > 0 {
+					v.command--
+				}
+			} else if v.selected > 0 {
+				v.selected--
+				v.command = 0
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.focusOnFixes {
+				if d := v.current(); d != nil && v.command < len(d.Commands)-1 {
+					v.command++
+				}
+			} else if v.selected < len(v.diagnostics)-1 {
+				v.selected++
+				v.command = 0
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			d := v.current()
+			if d == nil || len(d.Commands) == 0 || v.running {
+				return v, nil
+			}
+			command := d.Commands[v.command]
+			if _, ok := brew.FixArgs(command); !ok {
+				// Other commands may need sudo or a shell, the user runs them
+				v.result = fmt.Sprintf("Only brew commands run from here. Press c to copy `%s` and run it yourself.", command)
+				return v, nil
+			}
+			v.pendingCommand = command
+			v.dialog.SetMessage(fmt.Sprintf("Run `%s`?", v.pendingCommand))
+			v.dialog.Show()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("c"))):
+			d := v.current()
+			if d == nil || len(d.Commands) == 0 {
+				return v, nil
+			}
+			command := d.Commands[v.command]
+			if err := clipboard.WriteAll(command); err != nil {
+				v.result = fmt.Sprintf("Couldn't copy to the clipboard (%v): %s", err, command)
+			} else {
+				v.result = "Copied " + command
+			}
+		}
+		return v, nil
+
+	case components.DialogMsg:
+		if msg.Confirmed && v.pendingCommand != "" {
+			return v, v.runCommand(v.pendingCommand)
+		}
+		v.pendingCommand = ""
+		return v, nil
+
+	case ActionStartedMsg:
+		if v.requested {
+			v.requested = false
+			v.running = true
+			v.runningOp = msg.Op
+			v.result = ""
+		}
+		return v, nil
+
+	case ActionOutputMsg:
+		if v.running && msg.Op == v.runningOp {
+			v.result += msg.Line + "\n"
 		}
+		return v, nil
+
+	case ActionFinishedMsg:
+		if !v.running || msg.Op != v.runningOp {
+			return v, nil
+		}
+		v.running = false
+		v.runningOp = ""
+		v.pendingCommand = ""
+		v.result = strings.TrimSpace(strings.Join(append([]string{v.result}, msg.Logs...), "\n"))
+		if msg.Err != nil {
+			v.result = strings.TrimSpace(v.result + "\nError: " + msg.Err.Error())
+		}
+		// Re-run doctor to see whether the fix worked
+		return v, v.runDiagnostics()
 
 	case DiagnosticsLoadedMsg:
-		v.output = msg.Output
+		v.diagnostics = msg.Diagnostics
 		v.loading = false
-		v.viewport.SetContent(v.output)
+		v.selected = 0
+		v.command = 0
+		v.focusOnFixes = false
 		return v, nil
 
 	case ErrorMsgView:
 		v.loading = false
-		v.output = "Error running diagnostics: " + msg.Err.Error()
-		v.viewport.SetContent(v.output)
+		v.running = false
+		v.result = "Error running diagnostics: " + msg.Err.Error()
 		return v, nil
 	}
 
-	// Update viewport
-	var cmd tea.Cmd
-	v.viewport, cmd = v.viewport.Update(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
-	}
-
-	return v, tea.Batch(cmds...)
+	return v, nil
 }
 
 // View renders the view
@@ -97,32 +200,158 @@ func (v *DiagnosticsView) View() string {
 		return styles.AppStyle.Render(content)
 	}
 
-	helpText := "r: Refresh | ↑/↓: Scroll | Esc: Back"
-	help := styles.HelpStyle.Render(helpText)
+	var body string
+	if len(v.diagnostics) == 0 {
+		body = styles.SuccessMessageStyle.Render("✓ Your system is ready to brew.")
+	} else {
+		listWidth := v.width * 2 / 5
+		if listWidth < 30 {
+			listWidth = 30
+		}
+		detailWidth := v.width - listWidth - 6
+		if detailWidth < 30 {
+			detailWidth = 30
+		}
+		body = lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			v.renderList(listWidth),
+			v.renderDetail(detailWidth),
+		)
+	}
+
+	sections := []string{title, "", body}
+	if v.running {
+		sections = append(sections, "", styles.DimStyle.Render("Running "+v.pendingCommand+"..."))
+	} else if v.result != "" {
+		sections = append(sections, "", styles.KeyStyle.Render("Last command output:"), styles.DimStyle.Render(v.result))
+	}
+
+	helpText := "↑/↓: Navigate | Tab: Switch to fixes | Enter: Run brew fix | c: Copy fix | r: Refresh | Esc: Back"
+	sections = append(sections, "", styles.HelpStyle.Render(helpText))
+
+	content := styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+	if v.dialog.IsVisible() {
+		content = v.dialog.Overlay(content, v.width, v.height)
+	}
+	return content
+}
+
+func (v *DiagnosticsView) renderList(width int) string {
+	panelStyle := styles.ActivePanelStyle
+	if v.focusOnFixes {
+		panelStyle = styles.PanelStyle
+	}
+
+	var lines []string
+	lines = append(lines, styles.PanelTitleStyle.Render(fmt.Sprintf("⚠ Problems (%d)", len(v.diagnostics))))
+	for i, d := range v.diagnostics {
+		label := d.Title
+		if len(label) > width-16 && width > 20 {
+			label = label[:width-19] + "..."
+		}
+		line := fmt.Sprintf("%s %s", severityBadge(d.Severity), label)
+		if i == v.selected {
+			line = "▶ " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	return panelStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func (v *DiagnosticsView) renderDetail(width int) string {
+	panelStyle := styles.PanelStyle
+	if v.focusOnFixes {
+		panelStyle = styles.ActivePanelStyle
+	}
+
+	d := v.current()
+	if d == nil {
+		return panelStyle.Width(width).Render("")
+	}
+
+	wrap := lipgloss.NewStyle().Width(width - 4)
+	sections := []string{
+		styles.PanelTitleStyle.Render("ℹ️  Details"),
+		wrap.Render(styles.KeyStyle.Render(d.Title)),
+		styles.DimStyle.Render(fmt.Sprintf("Severity: %s • Kind: %s", d.Severity, d.Kind)),
+	}
+
+	if d.Message != "" {
+		sections = append(sections, "", wrap.Render(styles.ValueStyle.Render(d.Message)))
+	}
+
+	if len(d.Items) > 0 {
+		sections = append(sections, "", styles.KeyStyle.Render("Affected:"))
+		for _, item := range d.Items {
+			sections = append(sections, styles.ValueStyle.Render("  "+item))
+		}
+	}
 
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		title,
-		"",
-		v.viewport.View(),
-		"",
-		help,
-	)
+	if len(d.Commands) > 0 {
+		sections = append(sections, "", styles.KeyStyle.Render("Suggested fixes:"))
+		for i, command := range d.Commands {
+			line := "  $ " + command
+			if v.focusOnFixes && i == v.command {
+				line = styles.SelectedStyle.Render("▶ $ " + command)
+			}
+			if _, ok := brew.FixArgs(command); !ok {
+				line += styles.DimStyle.Render("  (copy with c)")
+			}
+			sections = append(sections, line)
+		}
+	}
 
-	return styles.AppStyle.Render(content)
+	return panelStyle.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (v *DiagnosticsView) current() *brew.Diagnostic {
+	if v.selected >= 0 && v.selected < len(v.diagnostics) {
+		return &v.diagnostics[v.selected]
+	}
+	return nil
 }
 
 func (v *DiagnosticsView) runDiagnostics() tea.Cmd {
 	v.loading = true
 	return func() tea.Msg {
 		ctx := context.Background()
-		output, err := v.client.Doctor(ctx)
+		diagnostics, err := v.client.Diagnose(ctx)
 		if err != nil {
 			return ErrorMsgView{Err: err}
 		}
-		return DiagnosticsLoadedMsg{Output: output}
+		return DiagnosticsLoadedMsg{Diagnostics: diagnostics}
+	}
+}
+
+// runCommand asks the app to run a suggested brew command, without a shell.
+// Its output arrives with the action messages once it starts.
+func (v *DiagnosticsView) runCommand(command string) tea.Cmd {
+	args, ok := brew.FixArgs(command)
+	if !ok {
+		return nil
+	}
+	v.requested = true
+	return func() tea.Msg {
+		return RequestBrewMsg{Args: args}
+	}
+}
+
+// severityBadge renders a short colored severity label
+func severityBadge(severity brew.DiagnosticSeverity) string {
+	switch severity {
+	case brew.SeverityError:
+		return styles.ErrorStyle.Render("[ERR ]")
+	case brew.SeverityWarning:
+		return styles.OutdatedStyle.Render("[WARN]")
+	default:
+		return styles.DimStyle.Render("[INFO]")
 	}
 }
 
 // Message types
-type DiagnosticsLoadedMsg struct{ Output string }
+type (
+	DiagnosticsLoadedMsg struct{ Diagnostics []brew.Diagnostic }
+)
@@ -124,11 +124,17 @@ func (v *HomeView) renderStats() string {
 	outdatedStat := fmt.Sprintf("%s %d", styles.KeyStyle.Render("Outdated:"), outdatedCount)
 	favStat := fmt.Sprintf("%s %d", styles.KeyStyle.Render("Favorites:"), favCount)
 
+	total, reclaimable := v.state.GetDiskUsage()
+	diskStat := fmt.Sprintf("%s %s", styles.KeyStyle.Render("Disk usage:"), brew.FormatSize(total))
+	reclaimStat := fmt.Sprintf("%s %s", styles.KeyStyle.Render("Reclaimable:"), brew.FormatSize(reclaimable))
+
 	stats := lipgloss.JoinVertical(
 		lipgloss.Left,
 		installedStat,
 		outdatedStat,
 		favStat,
+		diskStat,
+		reclaimStat,
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left, statsTitle, "", stats)
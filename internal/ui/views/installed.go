@@ -326,52 +326,13 @@ func (v *InstalledView) loadPackageInfo(pkg *brew.Package) tea.Cmd {
 }
 
 // Message types
-type (
-	NavigateToDetailsMsg struct{}
-	RequestUninstallMsg  struct{ Package brew.Package }
-	TogglePinMsg         struct {
-		PackageName string
-		Pinned      bool
-	}
-	RefreshPackagesMsg struct{}
-)
-
-// Bubble Tea commands
+type RefreshPackagesMsg struct{}
 
-func uninstallPackage(client brew.Client, pkg brew.Package) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		opts := brew.UninstallOptions{
-			Cask: pkg.Type == brew.TypeCask,
-		}
-		err := client.Uninstall(ctx, pkg.Name, opts)
-		if err != nil {
-			return ErrorMsgView{Err: err}
-		}
-		return SuccessMsgView{Msg: "Successfully uninstalled " + pkg.Name}
-	}
+type ErrorMsgView struct {
+	Err  error
+	Logs []string // Hook output
 }
-
-func togglePin(client brew.Client, packageName string, currentlyPinned bool) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		var err error
-		if currentlyPinned {
-			err = client.Unpin(ctx, packageName)
-		} else {
-			err = client.Pin(ctx, packageName)
-		}
-		if err != nil {
-			return ErrorMsgView{Err: err}
-		}
-
-		action := "pinned"
-		if currentlyPinned {
-			action = "unpinned"
-		}
-		return SuccessMsgView{Msg: "Successfully " + action + " " + packageName}
-	}
+type SuccessMsgView struct {
+	Msg  string
+	Logs []string // Hook output
 }
-
-type ErrorMsgView struct{ Err error }
-type SuccessMsgView struct{ Msg string }
@@ -0,0 +1,176 @@
+package views
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/ui/components"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+const (
+	minSideBySideWidth = 80 // Narrower terminals stack the panels in one column
+	minPanelWidth      = 30 // Narrowest a column may be resized to
+	minPanelHeight     = 6  // Border, title and a few rows
+
+	splitStep  = 0.05 // How far < and > move the split between the columns
+	weightStep = 5    // How much + and - grow or shrink the focused panel
+	minWeight  = 10
+	maxWeight  = 80
+)
+
+// dashboardLayout is where each dashboard panel goes. Rendering, scroll math
+// and mouse hit-testing all read from it, so they agree at every size.
+type dashboardLayout struct {
+	columns [][]PanelType                 // Panels shown, top to bottom in each column, left to right
+	rects   map[PanelType]components.Zone // Outer rectangle of each shown panel, borders included
+	status  components.Zone               // The status bar
+}
+
+// layoutSplits are the user-resizable proportions of the layout
+type layoutSplits struct {
+	left    float64           // Share of the width for the installed panel side by side
+	weights map[PanelType]int // Relative heights of the panels in a column
+}
+
+func defaultSplits() layoutSplits {
+	return layoutSplits{
+		left: 0.5,
+		weights: map[PanelType]int{
+			PanelInstalled:    40,
+			PanelSearch:       35,
+			PanelDependencies: 35,
+			PanelLogs:         30,
+		},
+	}
+}
+
+// resizeColumns moves the split between the columns by steps
+func (s *layoutSplits) resizeColumns(steps int) {
+	s.left += float64(steps) * splitStep
+	s.left = min(max(s.left, 0.2), 0.8)
+}
+
+// resizePanel grows or shrinks a panel's share of its column by steps
+func (s *layoutSplits) resizePanel(panel PanelType, steps int) {
+	s.weights[panel] = min(max(s.weights[panel]+steps*weightStep, minWeight), maxWeight)
+}
+
+// computeLayout fits the panels into width x height. Wide terminals get the
+// installed list beside the other panels, narrow ones get every panel in one
+// column, and terminals too small for that show only the focused panel.
+func computeLayout(width, height int, splits layoutSplits, focused PanelType) dashboardLayout {
+	contentHeight := max(height-1, 0) // Status bar
+	l := dashboardLayout{
+		rects:  make(map[PanelType]components.Zone),
+		status: components.Zone{Y: contentHeight, Width: width, Height: 1},
+	}
+
+	right := []PanelType{PanelSearch, PanelDependencies, PanelLogs}
+	all := []PanelType{PanelInstalled, PanelSearch, PanelDependencies, PanelLogs}
+	switch {
+	case width >= minSideBySideWidth && contentHeight >= len(right)*minPanelHeight:
+		leftWidth := int(float64(width) * splits.left)
+		leftWidth = min(max(leftWidth, minPanelWidth), width-minPanelWidth)
+		l.columns = [][]PanelType{{PanelInstalled}, right}
+		l.place(l.columns[0], components.Zone{Width: leftWidth, Height: contentHeight}, splits)
+		l.place(l.columns[1], components.Zone{X: leftWidth, Width: width - leftWidth, Height: contentHeight}, splits)
+	case contentHeight >= len(all)*minPanelHeight:
+		l.columns = [][]PanelType{all}
+		l.place(all, components.Zone{Width: width, Height: contentHeight}, splits)
+	default:
+		l.columns = [][]PanelType{{focused}}
+		l.rects[focused] = components.Zone{Width: width, Height: contentHeight}
+	}
+	return l
+}
+
+// place stacks panels in a column, sharing its height by their weights
+func (l *dashboardLayout) place(panels []PanelType, column components.Zone, splits layoutSplits) {
+	weights := make([]int, len(panels))
+	for i, panel := range panels {
+		weights[i] = splits.weights[panel]
+	}
+
+	y := column.Y
+	for i, height := range distribute(column.Height, weights, minPanelHeight) {
+		l.rects[panels[i]] = components.Zone{X: column.X, Y: y, Width: column.Width, Height: height}
+		y += height
+	}
+}
+
+// distribute splits total by weights, giving each part at least minimum when
+// there is room for it. Rounding leftovers go to the last part.
+func distribute(total int, weights []int, minimum int) []int {
+	sum := 0
+	for _, weight := range weights {
+		sum += weight
+	}
+	sizes := make([]int, len(weights))
+	if sum == 0 || len(weights) == 0 {
+		return sizes
+	}
+
+	used := 0
+	for i, weight := range weights {
+		sizes[i] = max(total*weight/sum, minimum)
+		used += sizes[i]
+	}
+	sizes[len(sizes)-1] += total - used
+
+	// Minimums may have overdrawn the total, take it back from the biggest parts
+	for i := len(sizes) - 1; sizes[i] < minimum; {
+		biggest := 0
+		for j := range sizes {
+			if sizes[j] > sizes[biggest] {
+				biggest = j
+			}
+		}
+		if biggest == i || sizes[biggest] <= minimum {
+			break
+		}
+		sizes[biggest]--
+		sizes[i]++
+	}
+	return sizes
+}
+
+// rect returns where a panel goes. Hidden panels get the whole content area,
+// which is what they get when focused on a small terminal.
+func (l dashboardLayout) rect(panel PanelType) components.Zone {
+	if rect, ok := l.rects[panel]; ok {
+		return rect
+	}
+	return components.Zone{Width: l.status.Width, Height: l.status.Y}
+}
+
+// panelAt returns the shown panel at a screen cell
+func (l dashboardLayout) panelAt(x, y int) (PanelType, bool) {
+	for panel, rect := range l.rects {
+		if rect.Contains(x, y) {
+			return panel, true
+		}
+	}
+	return 0, false
+}
+
+// inner returns the text area of a panel: its rectangle without the border
+// and padding of the panel style
+func inner(rect components.Zone) (width, height int) {
+	frameWidth, frameHeight := styles.PanelStyle.GetFrameSize()
+	return max(rect.Width-frameWidth, 1), max(rect.Height-frameHeight, 1)
+}
+
+// framePanel draws a panel's border around content, sized exactly to its
+// rectangle. Content that doesn't fit is cut rather than pushing the border.
+func framePanel(rect components.Zone, focused bool, content string) string {
+	style := styles.PanelStyle
+	if focused {
+		style = styles.ActivePanelStyle
+	}
+	width, height := inner(rect)
+
+	clip := lipgloss.NewStyle().MaxWidth(width).MaxHeight(height)
+	return style.
+		Width(width + style.GetHorizontalPadding()).
+		Height(height).
+		Render(clip.Render(content))
+}
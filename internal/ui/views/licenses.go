@@ -0,0 +1,238 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/license"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// LicensesView groups installed packages by license
+type LicensesView struct {
+	client brew.Client
+	state  *state.State
+	config *state.Config
+
+	groups         []license.Group
+	selected       int
+	scroll         int
+	disallowedOnly bool // Only show groups that violate the policy
+
+	width  int
+	height int
+}
+
+// NewLicensesView creates a new licenses view
+func NewLicensesView(client brew.Client, state *state.State, config *state.Config) *LicensesView {
+	return &LicensesView{
+		client: client,
+		state:  state,
+		config: config,
+	}
+}
+
+// SetSize sets the view size
+func (v *LicensesView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Init initializes the view
+func (v *LicensesView) Init() tea.Cmd {
+	v.regroup()
+	return nil
+}
+
+// Update handles messages
+func (v *LicensesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.selected > 0 {
+				v.selected--
+			}
+			v.clampSelection()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.selected < len(v.groups)-1 {
+				v.selected++
+			}
+			v.clampSelection()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("d"))):
+			if v.config.LicensePolicy.Enabled() {
+				v.disallowedOnly = !v.disallowedOnly
+				v.selected = 0
+				v.scroll = 0
+				v.regroup()
+			}
+		}
+
+	case PackagesLoadedMsg:
+		v.regroup()
+	}
+
+	return v, nil
+}
+
+// View renders the view
+func (v *LicensesView) View() string {
+	title := styles.TitleStyle.Render("Licenses")
+
+	installed := v.state.GetInstalledPackages()
+	summary := fmt.Sprintf("%d packages", len(installed))
+	if v.config.LicensePolicy.Enabled() {
+		disallowed := 0
+		for _, group := range license.GroupPackages(installed, v.config.LicensePolicy) {
+			if len(group.Violations) > 0 {
+				disallowed += len(group.Packages)
+			}
+		}
+		if disallowed > 0 {
+			summary += " • " + styles.ErrorStyle.Render(fmt.Sprintf("%d with disallowed licenses", disallowed))
+		} else {
+			summary += " • " + styles.SuccessMessageStyle.Render("all allowed by the license policy")
+		}
+	}
+
+	var body string
+	switch {
+	case len(installed) == 0:
+		body = styles.DimStyle.Render("No packages installed")
+	case len(v.groups) == 0:
+		body = styles.SuccessMessageStyle.Render("✓ No packages with disallowed licenses")
+	default:
+		listWidth := v.width / 2
+		if listWidth < 40 {
+			listWidth = 40
+		}
+		detailWidth := v.width - listWidth - 6
+		if detailWidth < 30 {
+			detailWidth = 30
+		}
+		body = lipgloss.JoinHorizontal(lipgloss.Top, v.renderGroups(listWidth), v.renderPackages(detailWidth))
+	}
+
+	helpText := "↑/↓: Navigate | Esc: Back"
+	if v.config.LicensePolicy.Enabled() {
+		helpText = "↑/↓: Navigate | d: Toggle disallowed only | Esc: Back"
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		styles.DimStyle.Render(summary),
+		"",
+		body,
+		"",
+		styles.HelpStyle.Render(helpText),
+	)
+	return styles.AppStyle.Render(content)
+}
+
+func (v *LicensesView) renderGroups(width int) string {
+	lines := []string{styles.PanelTitleStyle.Render(fmt.Sprintf("⚖  Licenses (%d)", len(v.groups)))}
+
+	end := v.scroll + v.visibleRows()
+	if end > len(v.groups) {
+		end = len(v.groups)
+	}
+	for i := v.scroll; i < end; i++ {
+		group := v.groups[i]
+		count := fmt.Sprintf("%d", len(group.Packages))
+		label := truncate(group.Expression, width-len(count)-8)
+		line := fmt.Sprintf("%-*s %s", width-len(count)-8, label, count)
+
+		switch {
+		case len(group.Violations) > 0:
+			line = styles.ErrorStyle.Render(line)
+		case group.Expression == license.Unknown:
+			line = styles.DimStyle.Render(line)
+		}
+		if i == v.selected {
+			line = "▶ " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	if end < len(v.groups) {
+		lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(v.groups)-end)))
+	}
+
+	return styles.ActivePanelStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func (v *LicensesView) renderPackages(width int) string {
+	if v.selected >= len(v.groups) {
+		return styles.PanelStyle.Width(width).Render("")
+	}
+	group := v.groups[v.selected]
+
+	lines := []string{styles.PanelTitleStyle.Render("📦 " + truncate(group.Expression, width-8))}
+	if len(group.Violations) > 0 {
+		lines = append(lines, styles.ErrorStyle.Render("Disallowed: "+strings.Join(group.Violations, ", ")), "")
+	}
+
+	limit := v.visibleRows()
+	for i, pkg := range group.Packages {
+		if i == limit {
+			lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("... %d more", len(group.Packages)-i)))
+			break
+		}
+		typeLabel := "formula"
+		if pkg.Type == brew.TypeCask {
+			typeLabel = "cask"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			styles.ValueStyle.Render(pkg.Name),
+			styles.DimStyle.Render(pkg.Version),
+			styles.DimStyle.Render("("+typeLabel+")")))
+	}
+
+	return styles.PanelStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func (v *LicensesView) regroup() {
+	groups := license.GroupPackages(v.state.GetInstalledPackages(), v.config.LicensePolicy)
+	if v.disallowedOnly {
+		var filtered []license.Group
+		for _, group := range groups {
+			if len(group.Violations) > 0 {
+				filtered = append(filtered, group)
+			}
+		}
+		groups = filtered
+	}
+	v.groups = groups
+	v.clampSelection()
+}
+
+func (v *LicensesView) visibleRows() int {
+	rows := v.height - 12
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+func (v *LicensesView) clampSelection() {
+	if v.selected >= len(v.groups) {
+		v.selected = len(v.groups) - 1
+	}
+	if v.selected < 0 {
+		v.selected = 0
+	}
+	if v.selected < v.scroll {
+		v.scroll = v.selected
+	}
+	if v.selected >= v.scroll+v.visibleRows() {
+		v.scroll = v.selected - v.visibleRows() + 1
+	}
+}
@@ -0,0 +1,371 @@
+package views
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// LogLevel is the severity of a log entry
+type LogLevel int
+
+const (
+	LevelInfo LogLevel = iota
+	LevelWarn
+	LevelError
+)
+
+// String returns the level name
+func (l LogLevel) String() string {
+	switch l {
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// LogEntry is one line in the logs panel
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	OpID    int    // Operation the entry belongs to, 0 for none
+	Op      string // Operation name, like "install jq"
+	Message string
+}
+
+// maxLogEntries bounds the session log
+const maxLogEntries = 1000
+
+// logPanel holds the session log and the state of the logs panel: scrolling,
+// search and filters
+type logPanel struct {
+	entries []LogEntry
+	ops     []LogEntry // First entry of each operation, for the operation filter
+	lastOp  int
+	op      int // Running operation new entries belong to, 0 for none
+	opName  string
+
+	scroll int // Entries scrolled up from the newest, 0 follows new entries
+
+	search textinput.Model
+	query  string // Applied search, highlighted in the entries
+
+	level    LogLevel // Only show this level when levelSet
+	levelSet bool
+	opFilter int // Only show this operation, 0 for all
+}
+
+func newLogPanel() *logPanel {
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "Search logs..."
+	search.CharLimit = 100
+
+	return &logPanel{search: search}
+}
+
+// begin starts an operation, the following entries belong to it until end
+func (p *logPanel) begin(name string) {
+	p.lastOp++
+	p.op, p.opName = p.lastOp, name
+}
+
+func (p *logPanel) end() {
+	p.op, p.opName = 0, ""
+}
+
+func (p *logPanel) add(level LogLevel, message string) {
+	entry := LogEntry{Time: time.Now(), Level: level, OpID: p.op, Op: p.opName, Message: message}
+	p.entries = append(p.entries, entry)
+	if len(p.entries) > maxLogEntries {
+		p.entries = p.entries[len(p.entries)-maxLogEntries:]
+	}
+	if entry.OpID != 0 && (len(p.ops) == 0 || p.ops[len(p.ops)-1].OpID != entry.OpID) {
+		p.ops = append(p.ops, entry)
+	}
+
+	// Keep the view still while the user is reading older entries
+	if p.scroll > 0 && p.visible(entry) {
+		p.scroll++
+	}
+}
+
+// visible reports whether an entry passes the level and operation filters
+func (p *logPanel) visible(entry LogEntry) bool {
+	if p.levelSet && entry.Level != p.level {
+		return false
+	}
+	return p.opFilter == 0 || entry.OpID == p.opFilter
+}
+
+// filtered returns the entries that pass the filters, oldest first
+func (p *logPanel) filtered() []LogEntry {
+	if !p.levelSet && p.opFilter == 0 {
+		return p.entries
+	}
+	var entries []LogEntry
+	for _, entry := range p.entries {
+		if p.visible(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// scrollBy scrolls towards older (positive) or newer (negative) entries
+func (p *logPanel) scrollBy(delta, visibleLines int) {
+	p.scroll += delta
+	maxScroll := len(p.filtered()) - visibleLines
+	if p.scroll > maxScroll {
+		p.scroll = maxScroll
+	}
+	if p.scroll < 0 {
+		p.scroll = 0
+	}
+}
+
+// cycleLevel steps the level filter through all, info, warn and error
+func (p *logPanel) cycleLevel() {
+	switch {
+	case !p.levelSet:
+		p.levelSet, p.level = true, LevelInfo
+	case p.level == LevelError:
+		p.levelSet = false
+	default:
+		p.level++
+	}
+	p.scroll = 0
+}
+
+// cycleOp steps the operation filter from all to the newest operation and
+// back through older ones
+func (p *logPanel) cycleOp() {
+	p.scroll = 0
+	if len(p.ops) == 0 {
+		p.opFilter = 0
+		return
+	}
+	if p.opFilter == 0 {
+		p.opFilter = p.ops[len(p.ops)-1].OpID
+		return
+	}
+	for i := len(p.ops) - 1; i > 0; i-- {
+		if p.ops[i].OpID == p.opFilter {
+			p.opFilter = p.ops[i-1].OpID
+			return
+		}
+	}
+	p.opFilter = 0
+}
+
+// findMatch scrolls to the next older (forward) or newer match of the search
+func (p *logPanel) findMatch(forward bool, visibleLines int) bool {
+	if p.query == "" {
+		return false
+	}
+	entries := p.filtered()
+	query := strings.ToLower(p.query)
+
+	// The entry at the bottom of the panel is the current position
+	current := len(entries) - 1 - p.scroll
+	for step := 1; step <= len(entries); step++ {
+		i := current + step
+		if forward {
+			i = current - step
+		}
+		if i < 0 || i >= len(entries) {
+			break
+		}
+		if strings.Contains(strings.ToLower(entries[i].Message), query) {
+			p.scroll = 0
+			p.scrollBy(len(entries)-1-i, visibleLines)
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns how many visible entries match the search
+func (p *logPanel) matches() int {
+	if p.query == "" {
+		return 0
+	}
+	query := strings.ToLower(p.query)
+	count := 0
+	for _, entry := range p.filtered() {
+		if strings.Contains(strings.ToLower(entry.Message), query) {
+			count++
+		}
+	}
+	return count
+}
+
+// handleSearchKey handles keys while the search input is focused
+func (p *logPanel) handleSearchKey(msg tea.KeyMsg, visibleLines int) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		p.search.Blur()
+		return nil
+	case "enter":
+		p.search.Blur()
+		p.query = strings.TrimSpace(p.search.Value())
+		p.scroll = 0
+		// Jump to the newest match
+		if p.query != "" {
+			p.scroll = -1
+			if !p.findMatch(true, visibleLines) {
+				p.scroll = 0
+			}
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	p.search, cmd = p.search.Update(msg)
+	return cmd
+}
+
+// filterLabel describes the active filters for the panel title
+func (p *logPanel) filterLabel() string {
+	var labels []string
+	if p.levelSet {
+		labels = append(labels, p.level.String())
+	}
+	if p.opFilter != 0 {
+		for _, op := range p.ops {
+			if op.OpID == p.opFilter {
+				labels = append(labels, fmt.Sprintf("#%d %s", op.OpID, op.Op))
+			}
+		}
+	}
+	if p.query != "" {
+		labels = append(labels, fmt.Sprintf("%q: %d matches", p.query, p.matches()))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// render renders the newest visible entries that fit in lines rows
+func (p *logPanel) render(width, lines int) string {
+	entries := p.filtered()
+	if len(entries) == 0 {
+		if len(p.entries) == 0 {
+			return styles.DimStyle.Render("No logs yet")
+		}
+		return styles.DimStyle.Render("No logs match the filters")
+	}
+
+	end := len(entries) - p.scroll
+	start := end - lines
+	if start < 0 {
+		start = 0
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		entry := entries[i]
+		prefix := entry.Time.Format("15:04:05") + " "
+		if entry.OpID != 0 {
+			prefix += fmt.Sprintf("#%d ", entry.OpID)
+		}
+		message := fitWidth(entry.Message, width-lipgloss.Width(prefix))
+
+		b.WriteString(styles.DimStyle.Render(prefix))
+		b.WriteString(highlight(message, p.query, logStyle(entry)))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// export writes the whole session log, ignoring filters, to a new file in dir
+func (p *logPanel) export(dir string) (string, error) {
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("brewst-log-%s.log", now.Format("20060102-150405")))
+
+	var b strings.Builder
+	for _, entry := range p.entries {
+		op := "-"
+		if entry.OpID != 0 {
+			op = fmt.Sprintf("#%d %s", entry.OpID, entry.Op)
+		}
+		fmt.Fprintf(&b, "%s %-5s [%s] %s\n", entry.Time.Format(time.RFC3339), strings.ToUpper(entry.Level.String()), op, entry.Message)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// levelOf classifies a log message by the markers the dashboard prefixes
+// messages with
+func levelOf(message string) LogLevel {
+	switch {
+	case strings.HasPrefix(message, "Error"), strings.HasPrefix(message, "✗"):
+		return LevelError
+	case strings.HasPrefix(message, "⚠"), strings.HasPrefix(message, "⏸"):
+		return LevelWarn
+	}
+	return LevelInfo
+}
+
+// logStyle colors an entry by level, and successful info entries green
+func logStyle(entry LogEntry) lipgloss.Style {
+	switch {
+	case entry.Level == LevelError:
+		return styles.ErrorStyle
+	case entry.Level == LevelWarn:
+		return styles.OutdatedStyle
+	case strings.HasPrefix(entry.Message, "✓"):
+		return styles.SuccessMessageStyle
+	}
+	return styles.DimStyle
+}
+
+// highlight renders s in style with case-insensitive matches of query reversed
+func highlight(s, query string, style lipgloss.Style) string {
+	if query == "" {
+		return style.Render(s)
+	}
+	lower, lowerQuery := strings.ToLower(s), strings.ToLower(query)
+	// Lower-casing can change byte lengths, only highlight when it didn't
+	if len(lower) != len(s) {
+		return style.Render(s)
+	}
+
+	var b strings.Builder
+	match := style.Reverse(true).Bold(true)
+	for {
+		i := strings.Index(lower, lowerQuery)
+		if i == -1 {
+			b.WriteString(style.Render(s))
+			return b.String()
+		}
+		if i > 0 {
+			b.WriteString(style.Render(s[:i]))
+		}
+		b.WriteString(match.Render(s[i : i+len(lowerQuery)]))
+		s, lower = s[i+len(lowerQuery):], lower[i+len(lowerQuery):]
+	}
+}
+
+// fitWidth cuts s to at most width terminal cells, marking the cut with "…"
+func fitWidth(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,258 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/manifest"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// UpgradeWarnings lists the upgrades that would go past a max version in the
+// team manifest. The manifest is only advisory, so a broken one is a warning too.
+func UpgradeWarnings(config *state.Config, packages []brew.OutdatedPackage) []string {
+	m, err := manifest.Load(config.ManifestPath)
+	if err != nil {
+		return []string{"Couldn't check the team manifest: " + err.Error()}
+	}
+
+	var warnings []string
+	for _, pkg := range packages {
+		entry, ok := m.Entry(pkg.Name, pkg.Type)
+		if !ok || pkg.Pinned || entry.Check(pkg.LatestVersion) != manifest.StatusTooNew {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %s is above the team manifest's max %s", pkg.Name, pkg.LatestVersion, entry.Max))
+	}
+	return warnings
+}
+
+// WithUpgradeWarnings prepends manifest warnings to an upgrade confirmation
+func WithUpgradeWarnings(question string, warnings []string) string {
+	if len(warnings) == 0 {
+		return question
+	}
+	return "⚠ " + strings.Join(warnings, "\n⚠ ") + "\n\n" + question
+}
+
+// DriftView compares installed packages and taps to the team manifest
+type DriftView struct {
+	client brew.Client
+	state  *state.State
+	config *state.Config
+
+	manifest *manifest.Manifest
+	results  []manifest.Result
+	err      error
+	loading  bool
+	cursor   int
+	scroll   int
+
+	width  int
+	height int
+}
+
+// NewDriftView creates a new drift report view
+func NewDriftView(client brew.Client, state *state.State, config *state.Config) *DriftView {
+	return &DriftView{
+		client: client,
+		state:  state,
+		config: config,
+	}
+}
+
+// SetSize sets the view size
+func (v *DriftView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Init initializes the view
+func (v *DriftView) Init() tea.Cmd {
+	return v.evaluate()
+}
+
+// Update handles messages
+func (v *DriftView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.keepCursorVisible()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.cursor < len(v.results)-1 {
+				v.cursor++
+			}
+			v.keepCursorVisible()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+			return v, v.evaluate()
+		}
+
+	case ManifestEvaluatedMsg:
+		v.loading = false
+		v.manifest = msg.Manifest
+		v.results = msg.Results
+		v.err = msg.Err
+		if v.cursor >= len(v.results) {
+			v.cursor = 0
+			v.scroll = 0
+		}
+
+	case PackagesLoadedMsg:
+		return v, v.evaluate()
+	}
+
+	return v, nil
+}
+
+// View renders the view
+func (v *DriftView) View() string {
+	sections := []string{styles.TitleStyle.Render("Team Manifest"), ""}
+
+	switch {
+	case v.config.ManifestPath == "":
+		sections = append(sections,
+			styles.DimStyle.Render("No team manifest configured."),
+			styles.DimStyle.Render(`Set "manifest_path" in ~/.config/brewst/config.json to a JSON or YAML manifest, e.g. in a team repo checkout.`))
+	case v.err != nil:
+		sections = append(sections, styles.ErrorStyle.Render("✗ "+v.err.Error()))
+	case v.loading && v.manifest == nil:
+		sections = append(sections, styles.DimStyle.Render("Comparing installed packages..."))
+	default:
+		sections = append(sections, styles.DimStyle.Render("Manifest: "+v.manifest.Path), v.renderSummary(), "")
+		sections = append(sections, v.renderResults()...)
+	}
+
+	sections = append(sections, "", styles.HelpStyle.Render("↑/↓: Navigate | r: Recheck | Esc: Back"))
+	return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (v *DriftView) renderSummary() string {
+	counts := make(map[manifest.Status]int)
+	for _, result := range v.results {
+		counts[result.Status]++
+	}
+	parts := []string{styles.SuccessMessageStyle.Render(fmt.Sprintf("%d compliant", counts[manifest.StatusCompliant]))}
+	for _, status := range []manifest.Status{manifest.StatusMissing, manifest.StatusTooOld, manifest.StatusTooNew} {
+		if counts[status] > 0 {
+			parts = append(parts, statusStyle(status).Render(fmt.Sprintf("%d %s", counts[status], status)))
+		}
+	}
+	return strings.Join(parts, " • ")
+}
+
+func (v *DriftView) renderResults() []string {
+	if len(v.results) == 0 {
+		return []string{styles.DimStyle.Render("The manifest is empty")}
+	}
+
+	header := fmt.Sprintf("  %-30s %-8s %-14s %-20s %s", "NAME", "KIND", "INSTALLED", "REQUIRED", "STATUS")
+	lines := []string{styles.DimStyle.Render(header)}
+
+	end := v.scroll + v.visibleRows()
+	if end > len(v.results) {
+		end = len(v.results)
+	}
+	for i := v.scroll; i < end; i++ {
+		result := v.results[i]
+		installed := result.Installed
+		if installed == "" {
+			installed = "-"
+		}
+		required := requirement(result.Entry)
+		if result.Kind == "tap" {
+			required = "tapped"
+		}
+		line := fmt.Sprintf("%-30s %-8s %-14s %-20s %s",
+			truncate(result.Name, 30),
+			result.Kind,
+			truncate(installed, 14),
+			truncate(required, 20),
+			statusStyle(result.Status).Render(result.Status.String()))
+		if i == v.cursor {
+			line = "▶ " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	if end < len(v.results) {
+		lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(v.results)-end)))
+	}
+	return lines
+}
+
+func (v *DriftView) evaluate() tea.Cmd {
+	if v.config.ManifestPath == "" {
+		return nil
+	}
+	v.loading = true
+	packages := v.state.GetInstalledPackages()
+	return func() tea.Msg {
+		m, err := manifest.Load(v.config.ManifestPath)
+		if err != nil {
+			return ManifestEvaluatedMsg{Err: err}
+		}
+		taps, err := v.client.ListTaps(context.Background())
+		if err != nil {
+			return ManifestEvaluatedMsg{Err: fmt.Errorf("failed to list taps: %w", err)}
+		}
+		return ManifestEvaluatedMsg{Manifest: m, Results: m.Evaluate(packages, taps)}
+	}
+}
+
+func (v *DriftView) visibleRows() int {
+	rows := v.height - 14
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+func (v *DriftView) keepCursorVisible() {
+	if v.cursor < v.scroll {
+		v.scroll = v.cursor
+	}
+	if v.cursor >= v.scroll+v.visibleRows() {
+		v.scroll = v.cursor - v.visibleRows() + 1
+	}
+}
+
+// requirement describes an entry's version bounds
+func requirement(entry manifest.Entry) string {
+	switch {
+	case entry.Min != "" && entry.Max != "":
+		return entry.Min + " – " + entry.Max
+	case entry.Min != "":
+		return ">= " + entry.Min
+	case entry.Max != "":
+		return "<= " + entry.Max
+	}
+	return "any"
+}
+
+func statusStyle(status manifest.Status) lipgloss.Style {
+	switch status {
+	case manifest.StatusMissing:
+		return styles.ErrorStyle
+	case manifest.StatusTooOld, manifest.StatusTooNew:
+		return styles.OutdatedStyle
+	}
+	return styles.SuccessMessageStyle
+}
+
+// ManifestEvaluatedMsg carries the drift report
+type ManifestEvaluatedMsg struct {
+	Manifest *manifest.Manifest
+	Results  []manifest.Result
+	Err      error
+}
@@ -3,9 +3,9 @@ package views
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lazar0169/brewst/internal/brew"
@@ -13,46 +13,31 @@ import (
 	"github.com/lazar0169/brewst/internal/ui/styles"
 )
 
-// OutdatedItem represents an outdated package in the list
-type OutdatedItem struct {
-	pkg brew.OutdatedPackage
-}
-
-func (i OutdatedItem) FilterValue() string { return i.pkg.Name }
-func (i OutdatedItem) Title() string {
-	name := i.pkg.Name
-	if i.pkg.Pinned {
-		name = styles.PinnedStyle.Render(name + " 📌")
-	} else {
-		name = styles.OutdatedStyle.Render(name + " ⚠")
-	}
-	return name
-}
-func (i OutdatedItem) Description() string {
-	return fmt.Sprintf("%s → %s", i.pkg.CurrentVersion, i.pkg.LatestVersion)
-}
-
-// OutdatedView shows outdated packages
+// OutdatedView shows outdated packages with selectable upgrades
 type OutdatedView struct {
 	client brew.Client
 	state  *state.State
+	config *state.Config
+
+	packages []brew.OutdatedPackage
+	checked  map[string]bool // Selected for upgrade, by outdatedKey
+	cursor   int
+	scroll   int
+
+	upgrading bool
+	message   string
 
-	list   list.Model
 	width  int
 	height int
 }
 
 // NewOutdatedView creates a new outdated packages view
-func NewOutdatedView(client brew.Client, state *state.State) *OutdatedView {
-	delegate := list.NewDefaultDelegate()
-	l := list.New([]list.Item{}, delegate, 80, 20)
-	l.Title = "Outdated Packages"
-	l.Styles.Title = styles.TitleStyle
-
+func NewOutdatedView(client brew.Client, state *state.State, config *state.Config) *OutdatedView {
 	return &OutdatedView{
-		client: client,
-		state:  state,
-		list:   l,
+		client:  client,
+		state:   state,
+		config:  config,
+		checked: make(map[string]bool),
 	}
 }
 
@@ -60,92 +45,426 @@ func NewOutdatedView(client brew.Client, state *state.State) *OutdatedView {
 func (v *OutdatedView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
-	v.list.SetSize(width-4, height-4)
 }
 
 // Init initializes the view
 func (v *OutdatedView) Init() tea.Cmd {
-	items := make([]list.Item, len(v.state.OutdatedPackages))
-	for i, pkg := range v.state.OutdatedPackages {
-		items[i] = OutdatedItem{pkg: pkg}
-	}
-	v.list.SetItems(items)
+	v.setPackages(v.state.OutdatedPackages)
 	return nil
 }
 
 // Update handles messages
 func (v *OutdatedView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("u"))):
-			// Upgrade selected package
-			if item, ok := v.list.SelectedItem().(OutdatedItem); ok {
-				return v, v.upgradePackage(item.pkg.Name)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.keepCursorVisible()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.cursor < len(v.packages)-1 {
+				v.cursor++
+			}
+			v.keepCursorVisible()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys(" ", "space"))):
+			// Toggle selection
+			if pkg := v.current(); pkg != nil {
+				v.checked[outdatedKey(*pkg)] = !v.checked[outdatedKey(*pkg)]
 			}
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+			// Select all unpinned, or clear the selection if everything is selected
+			all := true
+			for _, pkg := range v.packages {
+				if !pkg.Pinned && !v.checked[outdatedKey(pkg)] {
+					all = false
+				}
+			}
+			for _, pkg := range v.packages {
+				v.checked[outdatedKey(pkg)] = !all && !pkg.Pinned
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("u"))):
+			// Upgrade selected packages, or the one under the cursor
+			return v, v.upgradeSelected()
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("U"))):
-			// Upgrade all packages
-			return v, v.upgradeAll()
+			return v, v.requestUpgradeAll()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("g"))):
+			return v, v.toggleGreedy()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "i"))):
+			// Show the package under the cursor
+			if pkg := v.current(); pkg != nil {
+				v.state.SetSelectedPackage(&brew.Package{Name: pkg.Name, Type: pkg.Type, Installed: true, Outdated: true, Pinned: pkg.Pinned})
+				return v, func() tea.Msg {
+					return NavigateToDetailsMsg{}
+				}
+			}
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
 			// Refresh outdated list
+			v.message = "Refreshing..."
 			return v, func() tea.Msg {
 				return RefreshOutdatedMsg{}
 			}
 		}
-	}
+		return v, nil
 
-	// Update list
-	var cmd tea.Cmd
-	v.list, cmd = v.list.Update(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
+	case CommandMsg:
+		switch msg.Name {
+		case "upgrade-all":
+			return v, v.requestUpgradeAll()
+		case "greedy":
+			return v, v.toggleGreedy()
+		}
+		return v, nil
+
+	case ActionStartedMsg:
+		v.upgrading = true
+		v.message = msg.Message
+		return v, nil
+
+	case ActionFinishedMsg:
+		v.upgrading = false
+		if msg.Err != nil {
+			v.message = strings.Join(append(msg.Logs, "Error: "+msg.Err.Error()), "\n")
+			return v, nil
+		}
+		v.message = strings.Join(append(msg.Logs, msg.Msg), "\n")
+		v.checked = make(map[string]bool)
+		return v, nil
+
+	case OutdatedLoadedMsg:
+		v.setPackages(msg.Packages)
+		if !msg.Background {
+			v.message = ""
+		}
+		return v, nil
 	}
 
-	return v, tea.Batch(cmds...)
+	return v, nil
 }
 
 // View renders the view
 func (v *OutdatedView) View() string {
-	if len(v.state.OutdatedPackages) == 0 {
-		content := lipgloss.JoinVertical(
-			lipgloss.Left,
-			styles.TitleStyle.Render("Outdated Packages"),
+	title := "Outdated Packages"
+	if v.config.GreedyCasks {
+		title += " (including auto-updating casks)"
+	}
+
+	if len(v.packages) == 0 {
+		sections := []string{
+			styles.TitleStyle.Render(title),
 			"",
 			styles.SuccessMessageStyle.Render("All packages are up to date!"),
-		)
-		return styles.AppStyle.Render(content)
+		}
+		if v.message != "" {
+			sections = append(sections, "", styles.DimStyle.Render(v.message))
+		}
+		sections = append(sections, "", styles.HelpStyle.Render("g: Toggle auto-updating casks | r: Refresh | Esc: Back"))
+		return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
 	}
 
-	helpText := "u: Upgrade selected | U: Upgrade all | r: Refresh | Esc: Back"
-	help := styles.HelpStyle.Render(helpText)
+	nameWidth, versionWidth := 30, 18
+	header := fmt.Sprintf("    %-*s %-7s %*s   %-*s %-8s %s",
+		nameWidth, "NAME", "TYPE", versionWidth, "CURRENT", versionWidth, "LATEST", "UPDATE", "NOTES")
 
-	return v.list.View() + "\n" + help
+	lines := []string{
+		styles.TitleStyle.Render(fmt.Sprintf("%s (%d)", title, len(v.packages))),
+		"",
+		styles.DimStyle.Render(header),
+	}
+
+	end := v.scroll + v.visibleRows()
+	if end > len(v.packages) {
+		end = len(v.packages)
+	}
+	for i := v.scroll; i < end; i++ {
+		lines = append(lines, v.renderRow(i, nameWidth, versionWidth))
+	}
+	if end < len(v.packages) {
+		lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("    ↓ %d more", len(v.packages)-end)))
+	}
+
+	if v.upgrading || v.message != "" {
+		lines = append(lines, "", styles.DimStyle.Render(v.message))
+	}
+
+	selected := len(v.selectedPackages())
+	helpText := fmt.Sprintf("%d selected | Space: Select | a: All | u: Upgrade selected | U: Upgrade all | Enter: Details | g: Toggle auto-updating casks | r: Refresh | Esc: Back", selected)
+	lines = append(lines, "", styles.HelpStyle.Render(helpText))
+
+	return styles.AppStyle.Render(strings.Join(lines, "\n"))
 }
 
-func (v *OutdatedView) upgradePackage(name string) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		err := v.client.Upgrade(ctx, []string{name})
-		if err != nil {
-			return ErrorMsgView{Err: err}
+func (v *OutdatedView) renderRow(i, nameWidth, versionWidth int) string {
+	pkg := v.packages[i]
+
+	check := "[ ]"
+	if v.checked[outdatedKey(pkg)] {
+		check = "[x]"
+	}
+
+	pkgType := "formula"
+	if pkg.Type == brew.TypeCask {
+		pkgType = "cask"
+	}
+
+	var notes []string
+	if pkg.Pinned {
+		notes = append(notes, styles.PinnedStyle.Render("📌 pinned"))
+	}
+	if pkg.AutoUpdates {
+		notes = append(notes, styles.DimStyle.Render("auto-updates"))
+	}
+	if !pkg.Pinned && !v.config.AutoUpgrade.Allows(pkg.Name, pkg.Update) {
+		notes = append(notes, styles.DimStyle.Render("held by auto-upgrade policy"))
+	}
+
+	name := truncate(pkg.Name, nameWidth)
+	installed := fmt.Sprintf("%s %-*s %-7s %*s → ",
+		check,
+		nameWidth, name,
+		pkgType,
+		versionWidth, truncate(pkg.CurrentVersion, versionWidth))
+	update := fmt.Sprintf("%-*s %-8s",
+		versionWidth, truncate(pkg.LatestVersion, versionWidth),
+		pkg.Update)
+	notesText := strings.Join(notes, " ")
+
+	if i == v.cursor {
+		return styles.SelectedStyle.Render("▶ "+installed+update) + " " + notesText
+	}
+	if pkg.Pinned {
+		return "  " + styles.PinnedStyle.Render(installed+update) + " " + notesText
+	}
+	return "  " + styles.OutdatedStyle.Render(installed) + updateKindStyle(pkg.Update).Render(update) + " " + notesText
+}
+
+// updateKindStyle colors an update by how disruptive it is likely to be
+func updateKindStyle(kind brew.UpdateKind) lipgloss.Style {
+	switch kind {
+	case brew.UpdateMajor:
+		return styles.ErrorStyle
+	case brew.UpdateMinor:
+		return styles.OutdatedStyle
+	case brew.UpdatePatch:
+		return styles.InstalledStyle
+	case brew.UpdateRevision:
+		return styles.DimStyle
+	}
+	return lipgloss.NewStyle().Foreground(styles.Secondary)
+}
+
+// AutoUpgradePlan splits outdated packages into those upgrade-all may upgrade
+// under the auto-upgrade policy and those it holds back. Pinned packages are
+// left out since brew skips them anyway.
+func AutoUpgradePlan(policy state.AutoUpgradePolicy, packages []brew.OutdatedPackage) (allowed, held []brew.OutdatedPackage) {
+	for _, pkg := range packages {
+		switch {
+		case pkg.Pinned:
+		case policy.Allows(pkg.Name, pkg.Update):
+			allowed = append(allowed, pkg)
+		default:
+			held = append(held, pkg)
 		}
-		return SuccessMsgView{Msg: "Successfully upgraded " + name}
 	}
+	return allowed, held
 }
 
-func (v *OutdatedView) upgradeAll() tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		err := v.client.Upgrade(ctx, []string{})
-		if err != nil {
-			return ErrorMsgView{Err: err}
+// HeldSummary describes the updates the auto-upgrade policy held back
+func HeldSummary(held []brew.OutdatedPackage) string {
+	parts := make([]string, len(held))
+	for i, pkg := range held {
+		parts[i] = fmt.Sprintf("%s %s → %s (%s)", pkg.Name, pkg.CurrentVersion, pkg.LatestVersion, pkg.Update)
+	}
+	return "Held back by the auto-upgrade policy: " + strings.Join(parts, ", ")
+}
+
+// UpgradeBatch is packages one brew upgrade upgrades with the same options
+type UpgradeBatch struct {
+	Names   []string // Empty upgrades everything
+	Options brew.UpgradeOptions
+}
+
+// PlanUpgrade splits an upgrade into batches of packages that upgrade with
+// the same options, using the options each package was installed with. With
+// no names it plans an upgrade of everything: first the outdated packages
+// installed with options of their own, then the rest.
+func PlanUpgrade(config *state.Config, names []string, outdated []brew.OutdatedPackage, opts brew.UpgradeOptions) []UpgradeBatch {
+	var batches []UpgradeBatch
+	add := func(name string) {
+		options := opts
+		if installed, ok := config.InstallOptions[name]; ok {
+			options = installed.Upgrade()
+			options.Greedy = opts.Greedy
+		}
+		for i := range batches {
+			if batches[i].Options == options {
+				batches[i].Names = append(batches[i].Names, name)
+				return
+			}
+		}
+		batches = append(batches, UpgradeBatch{Names: []string{name}, Options: options})
+	}
+
+	if len(names) > 0 {
+		for _, name := range names {
+			add(name)
+		}
+		return batches
+	}
+	for _, pkg := range outdated {
+		if installed, ok := config.InstallOptions[pkg.Name]; ok && !pkg.Pinned && installed.Upgrade() != (brew.UpgradeOptions{}) {
+			add(pkg.Name)
+		}
+	}
+	return append(batches, UpgradeBatch{Options: opts})
+}
+
+// RunUpgrade runs the batches of an upgrade in order
+func RunUpgrade(ctx context.Context, client brew.Client, batches []UpgradeBatch) error {
+	for _, batch := range batches {
+		if err := client.Upgrade(ctx, batch.Names, batch.Options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPackages replaces the list, keeping selections for packages still outdated
+func (v *OutdatedView) setPackages(packages []brew.OutdatedPackage) {
+	v.packages = packages
+	checked := make(map[string]bool)
+	for _, pkg := range packages {
+		if v.checked[outdatedKey(pkg)] {
+			checked[outdatedKey(pkg)] = true
+		}
+	}
+	v.checked = checked
+	if v.cursor >= len(packages) {
+		v.cursor = len(packages) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	v.keepCursorVisible()
+}
+
+func (v *OutdatedView) current() *brew.OutdatedPackage {
+	if v.cursor >= 0 && v.cursor < len(v.packages) {
+		return &v.packages[v.cursor]
+	}
+	return nil
+}
+
+func (v *OutdatedView) visibleRows() int {
+	rows := v.height - 10
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+func (v *OutdatedView) keepCursorVisible() {
+	if v.cursor < v.scroll {
+		v.scroll = v.cursor
+	}
+	if v.cursor >= v.scroll+v.visibleRows() {
+		v.scroll = v.cursor - v.visibleRows() + 1
+	}
+}
+
+// outdatedKey identifies an outdated package, a formula and a cask can share
+// a name
+func outdatedKey(pkg brew.OutdatedPackage) string {
+	return string(pkg.Type) + "/" + pkg.Name
+}
+
+// selectedPackages returns the checked packages in list order
+func (v *OutdatedView) selectedPackages() []brew.OutdatedPackage {
+	var selected []brew.OutdatedPackage
+	for _, pkg := range v.packages {
+		if v.checked[outdatedKey(pkg)] {
+			selected = append(selected, pkg)
+		}
+	}
+	return selected
+}
+
+func (v *OutdatedView) upgradeSelected() tea.Cmd {
+	selected := v.selectedPackages()
+	if len(selected) == 0 {
+		if pkg := v.current(); pkg != nil {
+			selected = []brew.OutdatedPackage{*pkg}
 		}
-		return SuccessMsgView{Msg: "Successfully upgraded all packages"}
 	}
+
+	// Pinned packages can't be upgraded until they're unpinned
+	var outdated []brew.OutdatedPackage
+	for _, pkg := range selected {
+		if pkg.Pinned {
+			v.message = fmt.Sprintf("Skipping %s: pinned", pkg.Name)
+			continue
+		}
+		outdated = append(outdated, pkg)
+	}
+	if len(outdated) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return RequestUpgradeMsg{Packages: outdated}
+	}
+}
+
+// Commands returns the outdated view's actions for the command palette
+func (v *OutdatedView) Commands() []Command {
+	return []Command{
+		{Name: "upgrade-all", Title: "Upgrade all outdated packages", Keys: "U"},
+		{Name: "greedy", Title: "Toggle auto-updating casks", Keys: "g"},
+	}
+}
+
+// requestUpgradeAll asks the app to upgrade the packages the auto-upgrade
+// policy allows
+func (v *OutdatedView) requestUpgradeAll() tea.Cmd {
+	if len(v.packages) == 0 {
+		return nil
+	}
+	outdated := v.packages
+	return func() tea.Msg {
+		return RequestUpgradeAllMsg{Packages: outdated}
+	}
+}
+
+// toggleGreedy includes or hides casks that update themselves
+func (v *OutdatedView) toggleGreedy() tea.Cmd {
+	v.config.GreedyCasks = !v.config.GreedyCasks
+	if v.config.GreedyCasks {
+		v.message = "Including casks that auto-update..."
+	} else {
+		v.message = "Hiding casks that auto-update..."
+	}
+	if err := v.config.Save(); err != nil {
+		v.message += "\nError: failed to save config: " + err.Error()
+	}
+	return func() tea.Msg {
+		return RefreshOutdatedMsg{}
+	}
+}
+
+// truncate shortens s to width characters, marking the cut with "..."
+func truncate(s string, width int) string {
+	if len(s) <= width || width < 4 {
+		return s
+	}
+	return s[:width-3] + "..."
 }
 
 // Message types
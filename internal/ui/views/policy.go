@@ -0,0 +1,188 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/policy"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+)
+
+// InstallBlocked returns why the team policy blocks installing a package, or
+// "" when it is allowed. The policy file is read on every check so changes in
+// a team checkout apply right away, and an unreadable policy blocks installs.
+func InstallBlocked(config *state.Config, pkg brew.Package) string {
+	p, err := policy.Load(config.PolicyPath)
+	if err != nil {
+		return "Couldn't check the team install policy: " + err.Error()
+	}
+	return p.Explain(p.CheckPackage(pkg))
+}
+
+// TapBlocked returns why the team policy blocks adding a tap, or ""
+func TapBlocked(config *state.Config, tap string) string {
+	p, err := policy.Load(config.PolicyPath)
+	if err != nil {
+		return "Couldn't check the team install policy: " + err.Error()
+	}
+	return p.Explain(p.CheckTap(tap))
+}
+
+// PolicyView reports installed packages and taps the team policy doesn't allow
+type PolicyView struct {
+	client brew.Client
+	state  *state.State
+	config *state.Config
+
+	policy     *policy.Policy
+	violations []policy.Violation
+	err        error
+	loading    bool
+	scroll     int
+
+	width  int
+	height int
+}
+
+// NewPolicyView creates a new policy report view
+func NewPolicyView(client brew.Client, state *state.State, config *state.Config) *PolicyView {
+	return &PolicyView{
+		client: client,
+		state:  state,
+		config: config,
+	}
+}
+
+// SetSize sets the view size
+func (v *PolicyView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Init initializes the view
+func (v *PolicyView) Init() tea.Cmd {
+	return v.check()
+}
+
+// Update handles messages
+func (v *PolicyView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.scroll > 0 {
+				v.scroll--
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.scroll < len(v.violations)-1 {
+				v.scroll++
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+			return v, v.check()
+		}
+
+	case PolicyCheckedMsg:
+		v.loading = false
+		v.policy = msg.Policy
+		v.violations = msg.Violations
+		v.err = msg.Err
+		if v.scroll >= len(v.violations) {
+			v.scroll = 0
+		}
+
+	case PackagesLoadedMsg:
+		return v, v.check()
+	}
+
+	return v, nil
+}
+
+// View renders the view
+func (v *PolicyView) View() string {
+	sections := []string{styles.TitleStyle.Render("Install Policy"), ""}
+
+	switch {
+	case v.config.PolicyPath == "":
+		sections = append(sections,
+			styles.DimStyle.Render("No install policy configured."),
+			styles.DimStyle.Render(`Set "policy_path" in ~/.config/brewst/config.json to a policy file, e.g. in a team repo checkout.`))
+	case v.err != nil:
+		sections = append(sections, styles.ErrorStyle.Render("✗ "+v.err.Error()))
+	case v.loading && v.policy == nil:
+		sections = append(sections, styles.DimStyle.Render("Checking installed packages..."))
+	default:
+		sections = append(sections, styles.DimStyle.Render("Policy: "+v.policy.Path))
+		if v.policy.Message != "" {
+			sections = append(sections, styles.DimStyle.Render(v.policy.Message))
+		}
+		sections = append(sections, "")
+
+		if len(v.violations) == 0 {
+			sections = append(sections, styles.SuccessMessageStyle.Render("✓ Everything installed is allowed by the policy"))
+			break
+		}
+
+		sections = append(sections, styles.ErrorStyle.Render(fmt.Sprintf("✗ %d violations", len(v.violations))), "")
+		rows := v.height - 14
+		if rows < 5 {
+			rows = 5
+		}
+		end := v.scroll + rows
+		if end > len(v.violations) {
+			end = len(v.violations)
+		}
+		for _, violation := range v.violations[v.scroll:end] {
+			sections = append(sections, fmt.Sprintf("%s %s  %s",
+				styles.ErrorStyle.Render(fmt.Sprintf("%-30s", truncate(violation.Name, 30))),
+				styles.DimStyle.Render(fmt.Sprintf("%-8s", violation.Kind)),
+				truncate(violation.Reason, v.width-48)))
+		}
+		if end < len(v.violations) {
+			sections = append(sections, styles.DimStyle.Render(fmt.Sprintf("↓ %d more", len(v.violations)-end)))
+		}
+	}
+
+	sections = append(sections, "", styles.HelpStyle.Render("↑/↓: Scroll | r: Recheck | Esc: Back"))
+	return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (v *PolicyView) check() tea.Cmd {
+	if v.config.PolicyPath == "" {
+		return nil
+	}
+	v.loading = true
+	packages := v.state.GetInstalledPackages()
+	return func() tea.Msg {
+		p, err := policy.Load(v.config.PolicyPath)
+		if err != nil {
+			return PolicyCheckedMsg{Err: err}
+		}
+		taps, err := v.client.ListTaps(context.Background())
+		if err != nil {
+			return PolicyCheckedMsg{Err: fmt.Errorf("failed to list taps: %w", err)}
+		}
+		return PolicyCheckedMsg{Policy: p, Violations: p.Violations(packages, taps)}
+	}
+}
+
+// blockedLog shortens a policy explanation to one line for the logs panel
+func blockedLog(explanation string) string {
+	line, _, _ := strings.Cut(explanation, "\n")
+	return "✗ " + line
+}
+
+// Message types
+type (
+	PolicyCheckedMsg struct {
+		Policy     *policy.Policy
+		Violations []policy.Violation
+		Err        error
+	}
+	PolicyBlockedMsg struct{ Reason string }
+)
@@ -187,6 +187,7 @@ func (v *SearchView) View() string {
 
 func (v *SearchView) performSearch(query string) tea.Cmd {
 	v.searching = true
+	v.state.AddRecentSearch(query)
 	return func() tea.Msg {
 		ctx := context.Background()
 		results, err := v.client.Search(ctx, query)
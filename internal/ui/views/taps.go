@@ -3,8 +3,10 @@ package views
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lazar0169/brewst/internal/brew"
@@ -36,26 +38,44 @@ func (i TapItem) Description() string {
 type TapsView struct {
 	client brew.Client
 	state  *state.State
+	config *state.Config
+
+	list list.Model
+
+	// Adding a tap
+	input   textinput.Model
+	message string
 
-	list   list.Model
 	width  int
 	height int
 }
 
 // NewTapsView creates a new taps view
-func NewTapsView(client brew.Client, state *state.State) *TapsView {
+func NewTapsView(client brew.Client, state *state.State, config *state.Config) *TapsView {
 	delegate := list.NewDefaultDelegate()
 	l := list.New([]list.Item{}, delegate, 80, 20)
 	l.Title = "Homebrew Taps"
 	l.Styles.Title = styles.TitleStyle
 
+	input := textinput.New()
+	input.Prompt = "Tap: "
+	input.Placeholder = "user/repo"
+	input.CharLimit = 100
+
 	return &TapsView{
 		client: client,
 		state:  state,
+		config: config,
 		list:   l,
+		input:  input,
 	}
 }
 
+// CapturingInput reports whether the tap input owns the keyboard
+func (v *TapsView) CapturingInput() bool {
+	return v.input.Focused()
+}
+
 // SetSize sets the view size
 func (v *TapsView) SetSize(width, height int) {
 	v.width = width
@@ -65,12 +85,16 @@ func (v *TapsView) SetSize(width, height int) {
 
 // Init initializes the view
 func (v *TapsView) Init() tea.Cmd {
+	v.setItems()
+	return nil
+}
+
+func (v *TapsView) setItems() {
 	items := make([]list.Item, len(v.state.Taps))
 	for i, tap := range v.state.Taps {
 		items[i] = TapItem{tap: tap}
 	}
 	v.list.SetItems(items)
-	return nil
 }
 
 // Update handles messages
@@ -79,11 +103,62 @@ func (v *TapsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.input.Focused() {
+			switch msg.String() {
+			case "esc":
+				v.input.Blur()
+				v.input.SetValue("")
+				return v, nil
+			case "enter":
+				tap := strings.TrimSpace(v.input.Value())
+				v.input.Blur()
+				v.input.SetValue("")
+				return v, v.requestTap(tap)
+			}
+			var cmd tea.Cmd
+			v.input, cmd = v.input.Update(msg)
+			return v, cmd
+		}
+
 		switch msg.String() {
 		case "r":
 			// Refresh taps list
 			return v, loadTaps(v.client)
+		case "a":
+			return v, v.startAddTap()
+		}
+
+	case CommandMsg:
+		if msg.Name == "add-tap" {
+			return v, v.startAddTap()
+		}
+		return v, nil
+
+	case TapsLoadedMsg:
+		v.state.Taps = msg.Taps
+		v.setItems()
+		return v, nil
+
+	case ActionStartedMsg:
+		if strings.HasPrefix(msg.Op, "tap ") {
+			v.message = styles.DimStyle.Render(msg.Message)
 		}
+		return v, nil
+
+	case ActionFinishedMsg:
+		if !strings.HasPrefix(msg.Op, "tap ") {
+			return v, nil
+		}
+		if msg.Err != nil {
+			v.message = styles.ErrorStyle.Render("✗ " + msg.Err.Error())
+			return v, nil
+		}
+		v.message = styles.SuccessMessageStyle.Render("✓ " + msg.Msg)
+		return v, loadTaps(v.client)
+
+	case ErrorMsgView:
+		v.message = styles.ErrorStyle.Render("✗ " + msg.Err.Error())
+		return v, nil
 	}
 
 	// Update list
@@ -108,10 +183,41 @@ func (v *TapsView) View() string {
 		return styles.AppStyle.Render(content)
 	}
 
-	helpText := fmt.Sprintf("Total taps: %d | r: Refresh | Esc: Back", len(v.state.Taps))
+	helpText := fmt.Sprintf("Total taps: %d | a: Add tap | r: Refresh | Esc: Back", len(v.state.Taps))
 	help := styles.HelpStyle.Render(helpText)
 
-	return v.list.View() + "\n" + help
+	content := v.list.View() + "\n"
+	if v.input.Focused() {
+		content += v.input.View() + "\n"
+	} else if v.message != "" {
+		content += v.message + "\n"
+	}
+	content += help
+	return content
+}
+
+// Commands returns the taps view's actions for the command palette
+func (v *TapsView) Commands() []Command {
+	return []Command{{Name: "add-tap", Title: "Add a tap", Keys: "a"}}
+}
+
+// startAddTap focuses the input for the tap to add
+func (v *TapsView) startAddTap() tea.Cmd {
+	v.message = ""
+	v.input.Focus()
+	return textinput.Blink
+}
+
+// requestTap asks the app to add a tap, which checks the policy and asks
+// before tapping
+func (v *TapsView) requestTap(tap string) tea.Cmd {
+	if strings.Count(tap, "/") != 1 || strings.HasPrefix(tap, "-") {
+		v.message = styles.ErrorStyle.Render("✗ Taps are named user/repo")
+		return nil
+	}
+	return func() tea.Msg {
+		return RequestTapMsg{Tap: tap}
+	}
 }
 
 func loadTaps(client brew.Client) tea.Cmd {
@@ -125,4 +231,7 @@ func loadTaps(client brew.Client) tea.Cmd {
 	}
 }
 
-type TapsLoadedMsg struct{ Taps []brew.Tap }
+// Message types
+type (
+	TapsLoadedMsg struct{ Taps []brew.Tap }
+)
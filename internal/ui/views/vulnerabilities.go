@@ -0,0 +1,351 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/state"
+	"github.com/lazar0169/brewst/internal/ui/styles"
+	"github.com/lazar0169/brewst/internal/vuln"
+)
+
+// VulnerabilitiesView lists known vulnerabilities in installed packages
+type VulnerabilitiesView struct {
+	client brew.Client
+	state  *state.State
+	config *state.Config
+
+	findings  []vuln.Finding
+	selected  int
+	scroll    int
+	loading   bool
+	upgrading bool
+	message   string
+
+	width  int
+	height int
+}
+
+// NewVulnerabilitiesView creates a new vulnerabilities view
+func NewVulnerabilitiesView(client brew.Client, state *state.State, config *state.Config) *VulnerabilitiesView {
+	return &VulnerabilitiesView{
+		client: client,
+		state:  state,
+		config: config,
+	}
+}
+
+// SetSize sets the view size
+func (v *VulnerabilitiesView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Init initializes the view
+func (v *VulnerabilitiesView) Init() tea.Cmd {
+	v.findings = v.state.GetVulnerabilities()
+	v.clampSelection()
+	return nil
+}
+
+// Update handles messages
+func (v *VulnerabilitiesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if v.selected > 0 {
+				v.selected--
+			}
+			v.clampSelection()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if v.selected < len(v.findings)-1 {
+				v.selected++
+			}
+			v.clampSelection()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("u"))):
+			return v, v.requestUpgrade()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+			if v.config.OSVPath == "" {
+				return v, nil
+			}
+			v.loading = true
+			v.message = "Reloading OSV data..."
+			return v, func() tea.Msg {
+				return RefreshVulnerabilitiesMsg{}
+			}
+		}
+		return v, nil
+
+	case VulnerabilitiesLoadedMsg:
+		v.loading = false
+		if msg.Err != nil {
+			v.message = "Error loading OSV data: " + msg.Err.Error()
+			return v, nil
+		}
+		v.message = ""
+		v.findings = msg.Findings
+		v.clampSelection()
+		return v, nil
+
+	case ActionStartedMsg:
+		v.upgrading = true
+		v.message = msg.Message
+		return v, nil
+
+	case ActionFinishedMsg:
+		v.upgrading = false
+		if msg.Err != nil {
+			v.message = strings.Join(append(msg.Logs, "Error: "+msg.Err.Error()), "\n")
+			return v, nil
+		}
+		v.message = strings.Join(append(msg.Logs, msg.Msg), "\n")
+		return v, nil
+	}
+
+	return v, nil
+}
+
+// View renders the view
+func (v *VulnerabilitiesView) View() string {
+	title := styles.TitleStyle.Render("Vulnerabilities")
+
+	var body string
+	switch {
+	case v.config.OSVPath == "":
+		body = lipgloss.JoinVertical(lipgloss.Left,
+			styles.DimStyle.Render("No OSV database configured."),
+			styles.DimStyle.Render(`Set "osv_path" in ~/.config/brewst/config.json to a directory of OSV JSON files.`),
+		)
+	case v.loading && len(v.findings) == 0:
+		body = styles.DimStyle.Render("Checking installed packages...")
+	case len(v.findings) == 0:
+		body = styles.SuccessMessageStyle.Render("✓ No known vulnerabilities in installed formulae.")
+	default:
+		listWidth := v.width / 2
+		if listWidth < 40 {
+			listWidth = 40
+		}
+		detailWidth := v.width - listWidth - 6
+		if detailWidth < 30 {
+			detailWidth = 30
+		}
+		body = lipgloss.JoinHorizontal(lipgloss.Top, v.renderList(listWidth), v.renderDetail(detailWidth))
+	}
+
+	sections := []string{title, "", body}
+	if v.message != "" {
+		sections = append(sections, "", styles.DimStyle.Render(v.message))
+	}
+	helpText := "↑/↓: Navigate | u: Upgrade to fixed version | r: Reload OSV data | Esc: Back"
+	sections = append(sections, "", styles.HelpStyle.Render(helpText))
+
+	return styles.AppStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (v *VulnerabilitiesView) renderList(width int) string {
+	lines := []string{styles.PanelTitleStyle.Render(fmt.Sprintf("🛡  Affected packages (%d)", len(v.findings)))}
+
+	end := v.scroll + v.visibleRows()
+	if end > len(v.findings) {
+		end = len(v.findings)
+	}
+	for i := v.scroll; i < end; i++ {
+		finding := v.findings[i]
+		label := fmt.Sprintf("%s %s  %s", finding.Package.Name, finding.Package.Version, finding.Advisory.ID)
+		if finding.Advisory.Backport {
+			label += " (advisory)"
+		}
+		label = truncate(label, width-16)
+		line := vulnBadge(finding.Advisory.Severity) + " " + label
+		if i == v.selected {
+			line = "▶ " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	if end < len(v.findings) {
+		lines = append(lines, styles.DimStyle.Render(fmt.Sprintf("  ↓ %d more", len(v.findings)-end)))
+	}
+
+	return styles.ActivePanelStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func (v *VulnerabilitiesView) renderDetail(width int) string {
+	finding := v.current()
+	if finding == nil {
+		return styles.PanelStyle.Width(width).Render("")
+	}
+	advisory := finding.Advisory
+	wrap := lipgloss.NewStyle().Width(width - 4)
+
+	severity := advisory.Severity.String()
+	if advisory.Score > 0 {
+		severity += fmt.Sprintf(" (CVSS %.1f)", advisory.Score)
+	}
+
+	sections := []string{
+		styles.PanelTitleStyle.Render("ℹ️  " + advisory.ID),
+		wrap.Render(styles.KeyStyle.Render(advisory.Summary)),
+		"",
+		styles.KeyStyle.Render("Package: ") + styles.ValueStyle.Render(finding.Package.Name+" "+finding.Package.Version),
+		styles.KeyStyle.Render("Severity: ") + styles.ValueStyle.Render(severity),
+	}
+	if len(advisory.Aliases) > 0 {
+		sections = append(sections, styles.KeyStyle.Render("Aliases: ")+styles.ValueStyle.Render(strings.Join(advisory.Aliases, ", ")))
+	}
+	if !advisory.Published.IsZero() {
+		sections = append(sections, styles.KeyStyle.Render("Published: ")+styles.ValueStyle.Render(advisory.Published.Format("2006-01-02")))
+	}
+	if advisory.Ecosystem != "" {
+		sections = append(sections, styles.KeyStyle.Render("Source: ")+styles.ValueStyle.Render(advisory.Ecosystem+"/"+advisory.Package))
+	}
+
+	fixed := "no fixed version known"
+	if len(advisory.Fixed) > 0 {
+		fixed = strings.Join(advisory.Fixed, ", ")
+	}
+	sections = append(sections, styles.KeyStyle.Render("Fixed in: ")+styles.ValueStyle.Render(fixed))
+	if advisory.Backport {
+		sections = append(sections, wrap.Render(styles.OutdatedStyle.Render(
+			"Advisory only: "+advisory.Ecosystem+" patches fixes into its own builds, check whether the upstream release is affected")))
+	}
+	if finding.FixedIn != "" {
+		if latest, ok := v.availableFix(*finding); ok {
+			sections = append(sections, styles.SuccessMessageStyle.Render(fmt.Sprintf("⬆ %s is available, press u to upgrade", latest)))
+		} else {
+			sections = append(sections, styles.OutdatedStyle.Render("Homebrew doesn't have a fixed version yet"))
+		}
+	}
+
+	if advisory.Details != "" {
+		details := advisory.Details
+		if len(details) > 800 {
+			details = details[:800] + "..."
+		}
+		sections = append(sections, "", wrap.Render(styles.DimStyle.Render(details)))
+	}
+
+	if len(advisory.References) > 0 {
+		sections = append(sections, "", styles.KeyStyle.Render("References:"))
+		for i, ref := range advisory.References {
+			if i == 5 {
+				sections = append(sections, styles.DimStyle.Render(fmt.Sprintf("  ... %d more", len(advisory.References)-i)))
+				break
+			}
+			sections = append(sections, styles.DimStyle.Render("  "+truncate(ref, width-6)))
+		}
+	}
+
+	return styles.PanelStyle.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (v *VulnerabilitiesView) current() *vuln.Finding {
+	if v.selected >= 0 && v.selected < len(v.findings) {
+		return &v.findings[v.selected]
+	}
+	return nil
+}
+
+func (v *VulnerabilitiesView) visibleRows() int {
+	rows := v.height - 10
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+func (v *VulnerabilitiesView) clampSelection() {
+	if v.selected >= len(v.findings) {
+		v.selected = len(v.findings) - 1
+	}
+	if v.selected < 0 {
+		v.selected = 0
+	}
+	if v.selected < v.scroll {
+		v.scroll = v.selected
+	}
+	if v.selected >= v.scroll+v.visibleRows() {
+		v.scroll = v.selected - v.visibleRows() + 1
+	}
+}
+
+// availableFix returns the version Homebrew would upgrade to, if it fixes the finding
+func (v *VulnerabilitiesView) availableFix(finding vuln.Finding) (string, bool) {
+	outdated, ok := v.state.GetOutdatedPackage(finding.Package.Name)
+	if !ok || finding.FixedIn == "" {
+		return "", false
+	}
+	if brew.ParseVersion(outdated.LatestVersion).CompareUpstream(brew.ParseVersion(finding.FixedIn)) < 0 {
+		return outdated.LatestVersion, false
+	}
+	return outdated.LatestVersion, true
+}
+
+// requestUpgrade asks the app to upgrade the selected package, if the
+// upgrade fixes the finding
+func (v *VulnerabilitiesView) requestUpgrade() tea.Cmd {
+	finding := v.current()
+	if finding == nil || v.upgrading {
+		return nil
+	}
+	if _, ok := v.availableFix(*finding); !ok {
+		v.message = fmt.Sprintf("No upgrade for %s fixes %s yet", finding.Package.Name, finding.Advisory.ID)
+		return nil
+	}
+
+	outdated, _ := v.state.GetOutdatedPackage(finding.Package.Name)
+	if outdated.Pinned {
+		v.message = fmt.Sprintf("%s is pinned, unpin it to upgrade", finding.Package.Name)
+		return nil
+	}
+	return func() tea.Msg {
+		return RequestUpgradeMsg{Packages: []brew.OutdatedPackage{outdated}}
+	}
+}
+
+// vulnBadge renders a short colored severity label
+func vulnBadge(severity vuln.Severity) string {
+	switch severity {
+	case vuln.SeverityCritical:
+		return styles.ErrorStyle.Bold(true).Render("[CRIT]")
+	case vuln.SeverityHigh:
+		return styles.ErrorStyle.Render("[HIGH]")
+	case vuln.SeverityMedium:
+		return styles.OutdatedStyle.Render("[MED ]")
+	case vuln.SeverityLow:
+		return styles.DimStyle.Render("[LOW ]")
+	default:
+		return styles.DimStyle.Render("[ ?? ]")
+	}
+}
+
+// vulnMarker renders the compact marker shown next to affected packages
+func vulnMarker(severity vuln.Severity, count int) string {
+	marker := fmt.Sprintf("⛨%d", count)
+	switch severity {
+	case vuln.SeverityCritical, vuln.SeverityHigh:
+		return styles.ErrorStyle.Render(marker)
+	case vuln.SeverityMedium:
+		return styles.OutdatedStyle.Render(marker)
+	default:
+		return styles.DimStyle.Render(marker)
+	}
+}
+
+// Message types
+type (
+	VulnerabilitiesLoadedMsg struct {
+		Findings []vuln.Finding
+		Err      error
+	}
+	RefreshVulnerabilitiesMsg struct{}
+)
@@ -0,0 +1,332 @@
+package vuln
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+)
+
+// Severity ranks how serious an advisory is
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// String returns the severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityCritical:
+		return "critical"
+	case SeverityHigh:
+		return "high"
+	case SeverityMedium:
+		return "medium"
+	case SeverityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// parseSeverity parses GitHub and ecosystem severity labels
+func parseSeverity(label string) Severity {
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "CRITICAL":
+		return SeverityCritical
+	case "HIGH":
+		return SeverityHigh
+	case "MODERATE", "MEDIUM":
+		return SeverityMedium
+	case "LOW":
+		return SeverityLow
+	}
+	return SeverityUnknown
+}
+
+// severityFromScore maps a CVSS base score to its qualitative rating
+func severityFromScore(score float64) Severity {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	}
+	return SeverityUnknown
+}
+
+// Advisory is a vulnerability affecting one package
+type Advisory struct {
+	ID         string
+	Summary    string
+	Details    string
+	Aliases    []string // CVE and other IDs
+	Published  time.Time
+	Severity   Severity
+	Score      float64 // CVSS v3 base score, 0 when unknown
+	Package    string
+	Ecosystem  string
+	Fixed      []string // Upstream versions that fix the advisory
+	References []string
+
+	// Backport is set when the ecosystem's versions carry distribution
+	// revisions. Distributions patch fixes into older upstream versions, so
+	// whether an upstream release is affected can't be told from them and
+	// matches are only advisory.
+	Backport bool
+
+	ranges   []versionRange
+	versions []string // Explicitly affected versions
+}
+
+// versionRange is one introduced/fixed or introduced/last_affected span
+type versionRange struct {
+	introduced   string
+	fixed        string
+	lastAffected string
+}
+
+// Affects reports whether the advisory applies to a version. Homebrew
+// revisions are ignored since upstream versions don't have them.
+func (a *Advisory) Affects(version string) bool {
+	installed := brew.ParseVersion(version)
+
+	for _, v := range a.versions {
+		if installed.CompareUpstream(brew.ParseVersion(v)) == 0 {
+			return true
+		}
+	}
+
+	for _, r := range a.ranges {
+		if r.introduced != "0" && installed.CompareUpstream(brew.ParseVersion(r.introduced)) < 0 {
+			continue
+		}
+		if r.fixed != "" && installed.CompareUpstream(brew.ParseVersion(r.fixed)) >= 0 {
+			continue
+		}
+		if r.lastAffected != "" && installed.CompareUpstream(brew.ParseVersion(r.lastAffected)) > 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// FixedAfter returns the lowest fixed version newer than version, or ""
+func (a *Advisory) FixedAfter(version string) string {
+	installed := brew.ParseVersion(version)
+	best := ""
+	for _, fixed := range a.Fixed {
+		if installed.CompareUpstream(brew.ParseVersion(fixed)) >= 0 {
+			continue
+		}
+		if best == "" || brew.CompareVersions(fixed, best) < 0 {
+			best = fixed
+		}
+	}
+	return best
+}
+
+// Finding is an installed package affected by an advisory
+type Finding struct {
+	Package  brew.Package
+	Advisory *Advisory
+	FixedIn  string // Lowest fixed version above the installed one, "" when unknown
+}
+
+// DefaultEcosystems are the OSV ecosystems whose package names and version
+// ranges follow the upstream projects Homebrew packages. Bitnami lists
+// upstream releases in SEMVER ranges. Distributions like Debian only have
+// their own revisions, and OSS-Fuzz only has git commits. Language ecosystems
+// like npm or PyPI reuse the same names for unrelated packages.
+var DefaultEcosystems = []string{"Bitnami"}
+
+// Database holds OSV advisories indexed by package name
+type Database struct {
+	advisories map[string][]*Advisory
+	ecosystems map[string]bool // Ecosystems to keep advisories from
+}
+
+func newDatabase(ecosystems []string) *Database {
+	if len(ecosystems) == 0 {
+		ecosystems = DefaultEcosystems
+	}
+	db := &Database{
+		advisories: make(map[string][]*Advisory),
+		ecosystems: make(map[string]bool),
+	}
+	for _, ecosystem := range ecosystems {
+		db.ecosystems[strings.ToLower(ecosystem)] = true
+	}
+	return db
+}
+
+// Count returns the number of advisories loaded
+func (db *Database) Count() int {
+	count := 0
+	for _, advisories := range db.advisories {
+		count += len(advisories)
+	}
+	return count
+}
+
+func (db *Database) add(entry osvEntry) {
+	severity := parseSeverity(entry.DatabaseSpecific.Severity)
+	score := 0.0
+	for _, s := range entry.Severity {
+		if strings.HasPrefix(s.Type, "CVSS_V3") {
+			if base, ok := cvss3BaseScore(s.Score); ok {
+				score = base
+			}
+		}
+	}
+	if severity == SeverityUnknown {
+		severity = severityFromScore(score)
+	}
+
+	var references []string
+	for _, ref := range entry.References {
+		references = append(references, ref.URL)
+	}
+
+	for _, affected := range entry.Affected {
+		ecosystem := affected.Package.Ecosystem
+		if !db.ecosystems[strings.ToLower(baseEcosystem(ecosystem))] {
+			continue
+		}
+		// Installed versions are compared to the upstream part of the
+		// ecosystem's versions
+		upstream := func(version string) string { return upstreamVersion(ecosystem, version) }
+
+		advisory := &Advisory{
+			ID:         entry.ID,
+			Summary:    entry.Summary,
+			Details:    entry.Details,
+			Aliases:    entry.Aliases,
+			Published:  entry.Published,
+			Severity:   severity,
+			Score:      score,
+			Package:    affected.Package.Name,
+			Ecosystem:  ecosystem,
+			References: references,
+		}
+		for _, version := range affected.Versions {
+			advisory.versions = append(advisory.versions, upstream(version))
+			advisory.Backport = advisory.Backport || upstream(version) != version
+		}
+		if s := parseSeverity(affected.EcosystemSpecific.Severity); s != SeverityUnknown {
+			advisory.Severity = s
+		}
+
+		for _, r := range affected.Ranges {
+			// Git ranges are commit hashes, which can't be compared to versions
+			if r.Type == "GIT" {
+				continue
+			}
+			open := -1 // Range waiting for its fixed or last_affected event
+			for _, event := range r.Events {
+				switch {
+				case event.Introduced != "":
+					advisory.ranges = append(advisory.ranges, versionRange{introduced: upstream(event.Introduced)})
+					open = len(advisory.ranges) - 1
+				case event.Fixed != "" && open != -1:
+					advisory.ranges[open].fixed = upstream(event.Fixed)
+					if upstream(event.Fixed) != event.Fixed {
+						// Fixed by the distribution, not by an upstream release
+						advisory.Backport = true
+					} else {
+						advisory.Fixed = append(advisory.Fixed, event.Fixed)
+					}
+					open = -1
+				case event.LastAffected != "" && open != -1:
+					advisory.ranges[open].lastAffected = upstream(event.LastAffected)
+					advisory.Backport = advisory.Backport || upstream(event.LastAffected) != event.LastAffected
+					open = -1
+				}
+			}
+		}
+
+		name := strings.ToLower(affected.Package.Name)
+		db.advisories[name] = append(db.advisories[name], advisory)
+	}
+}
+
+// Check returns the advisories affecting installed formulae, most severe first
+func (db *Database) Check(packages []brew.Package) []Finding {
+	var findings []Finding
+	for _, pkg := range packages {
+		if pkg.Type != brew.TypeFormula || pkg.Version == "" {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, advisory := range db.advisories[advisoryName(pkg.Name)] {
+			// The same advisory can list the package under several ecosystems
+			if !seen[advisory.ID] && advisory.Affects(pkg.Version) {
+				seen[advisory.ID] = true
+				findings = append(findings, Finding{
+					Package:  pkg,
+					Advisory: advisory,
+					FixedIn:  advisory.FixedAfter(pkg.Version),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Advisory.Severity != findings[j].Advisory.Severity {
+			return findings[i].Advisory.Severity > findings[j].Advisory.Severity
+		}
+		if findings[i].Package.Name != findings[j].Package.Name {
+			return findings[i].Package.Name < findings[j].Package.Name
+		}
+		return findings[i].Advisory.ID < findings[j].Advisory.ID
+	})
+	return findings
+}
+
+// advisoryName maps a formula name to the upstream package name, dropping the
+// version suffix of versioned formulae like "openssl@3"
+func advisoryName(formula string) string {
+	name, _, _ := strings.Cut(strings.ToLower(formula), "@")
+	return name
+}
+
+// upstreamVersion drops what a distribution adds to upstream versions: the
+// epoch and Debian revision of "1:3.0.11-1~deb12u2", the "-r1" of Alpine.
+// Versions that change were built by the distribution, see Advisory.Backport.
+func upstreamVersion(ecosystem, version string) string {
+	switch strings.ToLower(baseEcosystem(ecosystem)) {
+	case "debian", "ubuntu":
+		if epoch, rest, ok := strings.Cut(version, ":"); ok && isDigits(epoch) {
+			version = rest
+		}
+		if i := strings.LastIndex(version, "-"); i > 0 {
+			version = version[:i]
+		}
+	case "alpine":
+		if i := strings.LastIndex(version, "-r"); i > 0 && isDigits(version[i+2:]) {
+			version = version[:i]
+		}
+	}
+	return version
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// baseEcosystem drops the release from ecosystems like "Debian:12"
+func baseEcosystem(ecosystem string) string {
+	base, _, _ := strings.Cut(ecosystem, ":")
+	return base
+}
@@ -1,15 +1,45 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lazar0169/brewst/internal/app"
+	"github.com/lazar0169/brewst/internal/brew"
 )
 
 func main() {
-	m := app.New()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			if err := runServe(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "sbom":
+			if err := runSBOM(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "metrics":
+			if err := runMetrics(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	flags := flag.NewFlagSet("brewst", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "record every brew command to a trace file, shown in the Debug view (ctrl+d)")
+	_ = flags.Parse(os.Args[1:])
+
+	m := app.New(*debug)
+	defer brew.StopTracing()
 
 	p := tea.NewProgram(
 		m,
@@ -18,6 +48,7 @@ func main() {
 	)
 
 	if _, err := p.Run(); err != nil {
+		brew.StopTracing()
 		fmt.Fprintf(os.Stderr, "Error running application: %v\n", err)
 		os.Exit(1)
 	}
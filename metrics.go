@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/metrics"
+	"github.com/lazar0169/brewst/internal/state"
+)
+
+// runMetrics prints package health metrics once, e.g. for the node exporter's textfile collector
+func runMetrics(args []string) error {
+	config, err := state.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	flags := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	openMetrics := flags.Bool("openmetrics", false, "write OpenMetrics text instead of the Prometheus text format")
+	greedy := flags.Bool("greedy", config.GreedyCasks, "count casks that update themselves as outdated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	snapshot, err := metrics.Collect(context.Background(), brew.NewClient(), *greedy)
+	if err != nil {
+		return err
+	}
+	return metrics.Write(os.Stdout, snapshot, time.Now(), *openMetrics)
+}
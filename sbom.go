@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/sbom"
+)
+
+// runSBOM writes a software bill of materials for the installed packages
+func runSBOM(args []string) error {
+	flags := flag.NewFlagSet("sbom", flag.ContinueOnError)
+	formatName := flags.String("format", "cyclonedx", "output format: cyclonedx or spdx")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := sbom.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	packages, err := brew.NewClient().ListInstalled(context.Background(), true, true)
+	if err != nil {
+		return err
+	}
+	doc := sbom.NewDocument(packages)
+
+	if *output == "" {
+		return sbom.Write(os.Stdout, doc, format)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := sbom.Write(file, doc, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
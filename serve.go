@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lazar0169/brewst/internal/brew"
+	"github.com/lazar0169/brewst/internal/metrics"
+	"github.com/lazar0169/brewst/internal/server"
+	"github.com/lazar0169/brewst/internal/state"
+)
+
+// runServe runs brewst as a local API server
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	socket := flags.String("socket", "", "listen on a Unix socket at this path")
+	addr := flags.String("addr", "127.0.0.1:7117", "listen on this loopback address when no socket is given")
+	token := flags.String("token", os.Getenv("BREWST_TOKEN"), "token required for mutating requests (default $BREWST_TOKEN, or generated)")
+	withMetrics := flags.Bool("metrics", false, "also serve Prometheus metrics at /metrics")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	config, err := state.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	listener, err := listen(*socket, *addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	fmt.Fprintf(os.Stderr, "brewst serving on %s\n", listener.Addr())
+
+	if *token == "" {
+		*token = generateToken()
+		fmt.Fprintf(os.Stderr, "Token for mutating requests: %s\n", *token)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := brew.NewClient()
+	srv := server.New(client, *token)
+	srv.SetConfig(config)
+	if *withMetrics {
+		ttl := time.Duration(config.CacheTTL) * time.Second
+		srv.Handle("GET /metrics", metrics.Handler(client, config.GreedyCasks, ttl))
+	}
+	return srv.Serve(ctx, listener)
+}
+
+// listen opens the Unix socket, or the TCP address if it is on the loopback interface
+func listen(socket, addr string) (net.Listener, error) {
+	if socket != "" {
+		// Remove a stale socket left by a previous run
+		if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(socket)
+		}
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(socket, 0600); err != nil {
+			listener.Close()
+			return nil, err
+		}
+		return listener, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, errors.New("refusing to listen on a non-loopback address: " + addr)
+		}
+	}
+	return net.Listen("tcp", addr)
+}
+
+func generateToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}